@@ -2,8 +2,11 @@ package core
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"runtime/debug"
 	"sync"
+	"time"
 
 	"github.com/google/uuid"
 )
@@ -12,8 +15,22 @@ type ActorResult struct {
 	Error   error
 	Action  int
 	Message interface{}
+	Sender  Actor
 	name    string
 	ID      uuid.UUID
+	future  *future
+	resume  chan struct{}
+	actor   *BasicActor // Actor processing the message, for Become
+}
+
+// PanicError is the failure reported when an actor's receive function panics
+type PanicError struct {
+	Value interface{}
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("actor panicked: %v", e.Value)
 }
 
 type Actor interface {
@@ -31,12 +48,38 @@ type Actor interface {
 type BasicActor struct {
 	id             uuid.UUID
 	name           string
-	mailbox        chan interface{}
+	mailbox        Mailbox
+	systemMailbox  Mailbox // System lane, drained before the mailbox
+	mu             sync.Mutex
 	stop           chan struct{}
+	done           chan struct{}
 	wg             *sync.WaitGroup
 	ctx            context.Context
 	ReceiveFunc    func(result *ActorResult) *ActorResult
 	failureChannel chan *ActorResult
+	supervised     bool
+	panicAction    int
+	self           Actor         // Actor embedding this BasicActor, for lifecycle hooks
+	restarting     *restartCause // Set when the supervisor stops it for a restart
+	restartedFrom  *restartCause // Set on the incarnation replacing a failed one
+	failure        error         // Failure awaiting the supervisor's decision
+	path           string
+	system         *ActorSystem
+	deadLetters    *DeadLetters
+	behaviors      []Behavior  // Set by Become, on top of ReceiveFunc
+	current        interface{} // Message being processed, for Stash
+	stash          []interface{}
+	unstashed      []interface{} // Processed before the mailbox
+	stashCapacity  int
+	parent         *BasicActor // Actor that spawned this one
+	children       []*BasicActor
+	supervisor     *Supervisor // Supervises the children
+	supervision    func(supervisor *Supervisor)
+	timers         map[string]Cancellable
+	receiveTimeout time.Duration
+	passivation    *passivation
+	identity       *identity     // Shared with the other incarnations
+	latency        time.Duration // Moving average of the processing time
 }
 
 //  recieveFunc func(result *ActorResult) *ActorResult
@@ -45,12 +88,28 @@ func NewBasicActor(name string) *BasicActor {
 }
 
 func NewBasicActorWithMailboxSize(name string, size int) *BasicActor {
-	return &BasicActor{
-		id:          uuid.New(),
-		name:        name,
-		mailbox:     make(chan interface{}, size),
-		stop:        make(chan struct{}),
+	return NewBasicActorWithMailbox(name, NewBoundedMailbox(size, MAILBOX_FAIL))
+}
+
+// NewBasicActorWithMailbox creates an actor using the given mailbox
+// implementation, e.g. an UnboundedMailbox or a BoundedMailbox with an
+// overflow policy
+func NewBasicActorWithMailbox(name string, mailbox Mailbox) *BasicActor {
+	a := &BasicActor{
+		id:            uuid.New(),
+		name:          name,
+		mailbox:       mailbox,
+		systemMailbox: NewUnboundedMailbox(),
+		stop:          make(chan struct{}),
+	}
+	if bounded, ok := mailbox.(*BoundedMailbox); ok && bounded.onOverflow == nil {
+		// The mailbox outlives a restart from Props, so publish from the
+		// current incarnation
+		bounded.SetOverflowHandler(func(msg interface{}) {
+			a.incarnation().publishDeadLetter(msg, ErrMailboxFull)
+		})
 	}
+	return a
 }
 
 func (a *BasicActor) GetID() uuid.UUID {
@@ -61,6 +120,25 @@ func (a *BasicActor) GetName() string {
 	return a.name
 }
 
+// GetPath returns the actor's path in its ActorSystem, empty if it was not
+// created through one
+func (a *BasicActor) GetPath() string {
+	return a.path
+}
+
+// basic gives the package access to the BasicActor embedded in user types
+func (a *BasicActor) basic() *BasicActor {
+	return a
+}
+
+// asBasicActor returns the BasicActor backing actor, if any
+func asBasicActor(actor Actor) *BasicActor {
+	if b, ok := actor.(interface{ basic() *BasicActor }); ok {
+		return b.basic()
+	}
+	return nil
+}
+
 func (a *BasicActor) SetWaitGroup(wg *sync.WaitGroup) {
 	a.wg = wg
 }
@@ -85,47 +163,217 @@ func (a *BasicActor) Start() {
 	if a.ctx == nil {
 		a.ctx = context.Background()
 	}
+	a.mu.Lock()
+	stop := a.stop
+	done := make(chan struct{})
+	a.done = done
+	a.mu.Unlock()
+	wg := a.wg
 	go func() {
+		passivated := false
 		defer func() {
+			a.cancelTimers()
+			a.stopChildren()
+			if restarting := a.postStop(); !restarting && !passivated {
+				a.terminate()
+			}
 			fmt.Printf("Actor %s finished.\n", a.id)
-			if a.wg != nil {
-				a.wg.Done()
+			if wg != nil {
+				wg.Done()
 			}
+			close(done)
 		}()
+		if err := a.preStart(); err != nil {
+			failure := &ActorResult{Error: err, Action: ACTOR_RESTART}
+			if !a.reportFailure(failure, stop) {
+				return
+			}
+		}
+		lastActivity := time.Now()
 		for {
+			// Stopping takes precedence over queued messages
 			select {
-			case msg := <-a.mailbox:
-				var result *ActorResult
-				if a.ReceiveFunc != nil {
-					actor := ActorResult{
-						Message: msg,
-						name:    a.name,
-						ID:      a.id,
-					}
-					result = a.ReceiveFunc(&actor)
-				} else {
-					result = &ActorResult{
-						Error: fmt.Errorf("no receive function defined for actor %s", a.GetID()),
-					}
+			case <-stop:
+				fmt.Printf("Stopping actor %s due to stop signal.\n", a.id)
+				return
+			case <-a.ctx.Done():
+				fmt.Printf("Stopping actor %s due to context cancellation.\n", a.id)
+				return
+			default:
+			}
+
+			if msg, ok := a.dequeue(); ok {
+				lastActivity = time.Now()
+				if !a.handleMessage(msg, stop) {
+					return
 				}
+				continue
+			}
 
-				if result.Error != nil {
-					fmt.Printf("Actor %s encountered a failure: %v\n", a.GetID(), result.Error)
-					a.failureChannel <- result
+			idle := a.startIdleTimers(lastActivity)
+			select {
+			case <-a.mailbox.Ready():
+			case <-a.systemMailbox.Ready():
+			case <-idle.receiveC():
+				if !a.handleMessage(ReceiveTimeout{}, stop) {
+					return
+				}
+			case <-idle.passivateC():
+				if a.passivate() {
+					passivated = true
+					return
 				}
-			case <-a.stop:
+			case <-stop:
 				fmt.Printf("Stopping actor %s due to stop signal.\n", a.id)
 				return
 			case <-a.ctx.Done():
 				fmt.Printf("Stopping actor %s due to context cancellation.\n", a.id)
 				return
 			}
+			idle.stop()
 		}
 	}()
 }
 
+// dequeue returns the next message to process: system messages first, then
+// unstashed messages, then the mailbox
+func (a *BasicActor) dequeue() (interface{}, bool) {
+	if msg, ok := a.systemMailbox.Dequeue(); ok {
+		return msg, true
+	}
+	if msg, ok := a.dequeueUnstashed(); ok {
+		return msg, true
+	}
+	return a.mailbox.Dequeue()
+}
+
+// handleMessage runs the receive function for msg and reports failures to the
+// supervisor. It returns false if the actor was stopped while doing so.
+func (a *BasicActor) handleMessage(msg interface{}, stop chan struct{}) bool {
+	actor := ActorResult{
+		Message: msg,
+		name:    a.name,
+		ID:      a.id,
+		actor:   a,
+	}
+	if env, ok := msg.(*envelope); ok {
+		actor.Message = env.message
+		actor.Sender = env.sender
+		actor.future = env.future
+	}
+	if failure, ok := msg.(*childFailure); ok {
+		// The children go down with the failure, as they would on a restart
+		a.stopChildren()
+		return a.reportFailure(failure.escalatedFailure(), stop)
+	}
+
+	a.mu.Lock()
+	a.current = msg
+	a.mu.Unlock()
+	started := time.Now()
+	result := a.invoke(&actor)
+	a.mu.Lock()
+	a.current = nil
+	a.recordLatency(time.Since(started))
+	a.mu.Unlock()
+	if result == nil || result.Error == nil {
+		return true
+	}
+
+	if actor.future != nil {
+		actor.future.complete(nil, result.Error)
+	}
+	if result.Message == nil {
+		result.Message = actor.Message
+	}
+	return a.reportFailure(result, stop)
+}
+
+// reportFailure sends a failed result to the supervisor and, when
+// supervised, waits for its decision. It returns false if the actor was
+// stopped meanwhile.
+func (a *BasicActor) reportFailure(result *ActorResult, stop chan struct{}) bool {
+	if result.ID == uuid.Nil {
+		result.ID = a.id
+		result.name = a.name
+	}
+	fmt.Printf("Actor %s encountered a failure: %v\n", a.GetID(), result.Error)
+	if panicErr, ok := result.Error.(*PanicError); ok {
+		fmt.Printf("%s\n", panicErr.Stack)
+	}
+	if a.failureChannel == nil {
+		return true
+	}
+	if a.supervised {
+		// Suspend until the supervisor has decided what to do
+		result.resume = make(chan struct{})
+	}
+	a.mu.Lock()
+	a.failure = result.Error
+	a.mu.Unlock()
+	select {
+	case a.failureChannel <- result:
+	case <-stop:
+		return false
+	}
+	if result.resume != nil {
+		select {
+		case <-result.resume:
+		case <-stop:
+			return false
+		case <-a.ctx.Done():
+			return false
+		}
+	}
+	a.mu.Lock()
+	a.failure = nil
+	a.mu.Unlock()
+	return true
+}
+
+// invoke calls the receive function, turning a panic into a failed result
+// carrying the panic value, stack trace and offending message
+func (a *BasicActor) invoke(actor *ActorResult) (result *ActorResult) {
+	defer func() {
+		if r := recover(); r != nil {
+			result = &ActorResult{
+				Error:   &PanicError{Value: r, Stack: debug.Stack()},
+				Action:  a.panicAction,
+				Message: actor.Message,
+				name:    a.name,
+				ID:      a.id,
+			}
+		}
+	}()
+
+	receive := a.receiveFunc()
+	if receive == nil {
+		return &ActorResult{
+			Error: fmt.Errorf("no receive function defined for actor %s", a.GetID()),
+		}
+	}
+	return receive(actor)
+}
+
+// SetPanicAction sets the action reported to the supervisor when the receive
+// function panics, ACTOR_RESTART by default
+func (a *BasicActor) SetPanicAction(action int) {
+	a.panicAction = action
+}
+
+// Stop stops the actor. Called on a reference taken before a restart from
+// Props, it stops the current incarnation.
 func (a *BasicActor) Stop() {
+	if current := a.incarnation(); current != a {
+		current.Stop()
+		return
+	}
 	fmt.Printf("Stopping actor %s...\n", a.id)
+	if a.stopPassivated() {
+		return
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
 	select {
 	case <-a.stop:
 		// Already closed
@@ -134,15 +382,188 @@ func (a *BasicActor) Stop() {
 	}
 }
 
+// awaitTermination blocks until the run loop started by Start has exited
+func (a *BasicActor) awaitTermination() {
+	a.mu.Lock()
+	done := a.done
+	a.mu.Unlock()
+	if done != nil {
+		<-done
+	}
+}
+
+// reincarnate prepares a stopped actor to be started again
+func (a *BasicActor) reincarnate() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.stop = make(chan struct{})
+	a.done = nil
+	a.failure = nil
+	a.behaviors = nil
+	// Stashed messages survive the restart, ahead of the mailbox
+	a.unstash()
+}
+
+// identity is shared by the incarnations of an actor. An actor restarted
+// from Props is a new instance, so references taken before the restart find
+// the running one through it, and death watch is kept here rather than on
+// the incarnations.
+type identity struct {
+	mu         sync.Mutex
+	current    *BasicActor
+	watchers   map[uuid.UUID]Actor     // Notified when the actor terminates
+	watching   map[uuid.UUID]*identity // Actors it watches, by ID
+	terminated bool
+}
+
+func (a *BasicActor) sharedIdentity() *identity {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.identity == nil {
+		a.identity = &identity{current: a}
+	}
+	return a.identity
+}
+
+// incarnation returns the current incarnation of the actor, which is a
+// itself unless a was replaced by a restart from Props
+func (a *BasicActor) incarnation() *BasicActor {
+	id := a.sharedIdentity()
+	id.mu.Lock()
+	defer id.mu.Unlock()
+	return id.current
+}
+
+// adopt makes a freshly built actor the next incarnation of prev, keeping its
+// identity and mailbox so existing references keep working
+func (a *BasicActor) adopt(prev *BasicActor) {
+	id := prev.sharedIdentity()
+	a.identity = id
+	id.mu.Lock()
+	id.current = a
+	id.mu.Unlock()
+	a.id = prev.id
+	a.name = prev.name
+	a.path = prev.path
+	a.system = prev.system
+	a.mailbox = prev.mailbox
+	a.systemMailbox = prev.systemMailbox
+	a.deadLetters = prev.deadLetters
+	a.stashCapacity = prev.stashCapacity
+	a.parent = prev.getParent()
+	prev.mu.Lock()
+	a.supervision = prev.supervision
+	prev.mu.Unlock()
+	a.passivation = prev.sharedPassivation()
+	prev.mu.Lock()
+	a.unstashed = append(prev.stash, prev.unstashed...)
+	prev.stash = nil
+	prev.unstashed = nil
+	prev.mu.Unlock()
+}
+
 // func (a *BasicActor) ReceiveMessage(msg interface{}) *ActorResult {
 // 	fmt.Printf("!!!Actor received message: %v\n", msg)
 // 	return &ActorResult{}
 // }
 
 func (a *BasicActor) SendMessage(msg interface{}) {
-	select {
-	case a.mailbox <- msg:
-	default:
-		fmt.Printf("Actor %s mailbox full, dropping message: %v\n", a.id, msg)
+	if err := a.tell(msg); err != nil {
+		fmt.Printf("Actor %s could not accept message %v: %v\n", a.id, msg, err)
+	}
+}
+
+// tell enqueues msg, reporting a message the mailbox rejected or sent to a
+// stopped actor as an error. System messages skip the mailbox and are never
+// rejected.
+func (a *BasicActor) tell(msg interface{}) error {
+	if a.isTerminated() {
+		a.publishDeadLetter(msg, ErrActorStopped)
+		return ErrActorStopped
+	}
+	if isSystemMessage(msg) {
+		err := a.systemMailbox.Enqueue(msg)
+		a.activate()
+		return err
+	}
+	if err := a.mailbox.Enqueue(msg); err != nil {
+		if !errors.Is(err, ErrMailboxFull) {
+			// Full mailboxes publish through their overflow handler
+			a.publishDeadLetter(msg, err)
+		}
+		return err
+	}
+	if a.isTerminated() {
+		// Stopped while enqueueing, the message will never be processed
+		a.drainToDeadLetters()
+	}
+	a.activate()
+	return nil
+}
+
+// SetDeadLetters routes the messages this actor cannot deliver to
+// deadLetters: messages sent after it stopped, left in its mailbox when it
+// stopped or discarded on a restart, and dropped by a BoundedMailbox without
+// an overflow handler of its own
+func (a *BasicActor) SetDeadLetters(deadLetters *DeadLetters) {
+	a.deadLetters = deadLetters
+}
+
+func (a *BasicActor) publishDeadLetter(msg interface{}, reason error) {
+	if a.deadLetters != nil {
+		a.deadLetters.Publish(deadLetter(msg, a.outer(), reason))
+	}
+}
+
+// drainToDeadLetters empties the mailboxes of a stopped actor into its dead
+// letter sink
+func (a *BasicActor) drainToDeadLetters() {
+	if a.deadLetters == nil {
+		return
+	}
+	a.mu.Lock()
+	a.unstash()
+	a.mu.Unlock()
+	for {
+		msg, ok := a.dequeue()
+		if !ok {
+			return
+		}
+		a.publishDeadLetter(msg, ErrActorStopped)
+	}
+}
+
+func (a *BasicActor) isTerminated() bool {
+	id := a.sharedIdentity()
+	id.mu.Lock()
+	defer id.mu.Unlock()
+	return id.terminated
+}
+
+// MailboxSize returns the number of messages waiting to be processed
+func (a *BasicActor) MailboxSize() int {
+	return a.mailbox.Len()
+}
+
+// drainMailbox discards every queued and stashed message as dead letters
+func (a *BasicActor) drainMailbox() {
+	a.discardStash()
+	for {
+		msg, ok := a.mailbox.Dequeue()
+		if !ok {
+			return
+		}
+		a.publishDeadLetter(msg, ErrActorRestarted)
+	}
+}
+
+// resumeActor releases an actor suspended after reporting this failure
+func (r *ActorResult) resumeActor() {
+	if r.resume != nil {
+		select {
+		case <-r.resume:
+		default:
+			close(r.resume)
+		}
 	}
 }
@@ -1,6 +1,13 @@
 package core
 
-import "sync"
+import (
+	"fmt"
+	"path"
+	"sort"
+	"sync"
+
+	"github.com/google/uuid"
+)
 
 const DefaultRegistrySize = 100
 
@@ -26,9 +33,67 @@ func (ar *ActorRegistry) RegisterActor(actor Actor) {
 	ar.actors[actor.GetName()] = actor
 }
 
+// RegisterActorAs registers an actor under key, failing if the key is taken
+func (ar *ActorRegistry) RegisterActorAs(key string, actor Actor) error {
+	ar.Lock()
+	defer ar.Unlock()
+	if _, exists := ar.actors[key]; exists {
+		return fmt.Errorf("actor %s already registered", key)
+	}
+	ar.actors[key] = actor
+	return nil
+}
+
+// UnregisterActor removes the actor registered under key
+func (ar *ActorRegistry) UnregisterActor(key string) {
+	ar.Lock()
+	defer ar.Unlock()
+	delete(ar.actors, key)
+}
+
 func (ar *ActorRegistry) GetActor(id string) (Actor, bool) {
 	ar.RLock()
 	defer ar.RUnlock()
 	actor, exists := ar.actors[id]
 	return actor, exists
 }
+
+// FindActors returns the actors whose key matches pattern, using path.Match
+// syntax, ordered by key
+func (ar *ActorRegistry) FindActors(pattern string) []Actor {
+	ar.RLock()
+	defer ar.RUnlock()
+
+	keys := make([]string, 0)
+	for key := range ar.actors {
+		if matched, _ := path.Match(pattern, key); matched {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	actors := make([]Actor, 0, len(keys))
+	for _, key := range keys {
+		actors = append(actors, ar.actors[key])
+	}
+	return actors
+}
+
+// replaceActor points key at a new incarnation of the registered actor
+func (ar *ActorRegistry) replaceActor(key string, actor Actor) {
+	ar.Lock()
+	defer ar.Unlock()
+	if _, exists := ar.actors[key]; exists {
+		ar.actors[key] = actor
+	}
+}
+
+// unregisterActorIf removes key only while it still points at the actor with
+// the given id, so a stale actor cannot unregister its replacement
+func (ar *ActorRegistry) unregisterActorIf(key string, id uuid.UUID) {
+	ar.Lock()
+	defer ar.Unlock()
+	if actor, exists := ar.actors[key]; exists && actor.GetID() == id {
+		delete(ar.actors, key)
+	}
+}
@@ -0,0 +1,203 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+const (
+	// UserPath is the root of all actors created with ActorSystem.ActorOf
+	UserPath = "/user"
+	// SystemPath is the root of actors owned by the ActorSystem itself
+	SystemPath = "/system"
+)
+
+// ActorSystem owns the root guardian supervisor, the registry, the event
+// stream, the dead letters and the default broker, and addresses actors by
+// hierarchical paths such as /user/orders/worker-3
+type ActorSystem struct {
+	name        string
+	guardian    *Supervisor
+	registry    *ActorRegistry
+	eventStream *EventStream
+	broker      MessageBroker
+	deadLetters *DeadLetters
+	logger      Logger
+	scheduler   *Scheduler
+}
+
+// NewActorSystem creates an actor system whose actors live until ctx is done
+// or Shutdown is called
+func NewActorSystem(ctx context.Context, name string) *ActorSystem {
+	eventStream := NewEventStream()
+	deadLetters := NewDeadLetters()
+	deadLetters.SetEventStream(eventStream)
+	broker := NewInMemoryBroker()
+	broker.SetDeadLetters(deadLetters)
+	return &ActorSystem{
+		name:        name,
+		guardian:    NewSupervisor(ctx),
+		registry:    NewActorRegistry(),
+		eventStream: eventStream,
+		broker:      broker,
+		deadLetters: deadLetters,
+		logger:      defaultLogger,
+		scheduler:   NewScheduler(ctx),
+	}
+}
+
+func (s *ActorSystem) GetName() string {
+	return s.name
+}
+
+// Guardian returns the root supervisor of the system's actors
+func (s *ActorSystem) Guardian() *Supervisor {
+	return s.guardian
+}
+
+// Registry returns the registry of actors keyed by path
+func (s *ActorSystem) Registry() *ActorRegistry {
+	return s.registry
+}
+
+// EventStream returns the stream of system events, such as the DeadLetter
+// of every message the system could not deliver
+func (s *ActorSystem) EventStream() *EventStream {
+	return s.eventStream
+}
+
+// DeadLetters returns the sink collecting the messages the system's actors
+// and default broker could not deliver
+func (s *ActorSystem) DeadLetters() *DeadLetters {
+	return s.deadLetters
+}
+
+// Scheduler returns the scheduler whose deliveries stop with the system
+func (s *ActorSystem) Scheduler() *Scheduler {
+	return s.scheduler
+}
+
+// Logger returns the logger handed to actors through their ActorContext
+func (s *ActorSystem) Logger() Logger {
+	return s.logger
+}
+
+// SetLogger replaces the default logger writing to stdout
+func (s *ActorSystem) SetLogger(logger Logger) {
+	s.logger = logger
+}
+
+func (s *ActorSystem) Broker() MessageBroker {
+	return s.broker
+}
+
+// SetBroker replaces the default in-memory broker
+func (s *ActorSystem) SetBroker(broker MessageBroker) {
+	s.broker = broker
+}
+
+// ActorOf creates an actor from props, registers it under /user/<name> and
+// starts it under the guardian. name may contain '/' to group actors, e.g.
+// "orders/worker-3".
+func (s *ActorSystem) ActorOf(props *Props, name string) (Actor, error) {
+	if err := validateActorName(name); err != nil {
+		return nil, err
+	}
+	return s.spawn(props, UserPath+"/"+name, nil)
+}
+
+// spawn creates, registers and starts an actor at path, recording it as a
+// child of parent when set
+func (s *ActorSystem) spawn(props *Props, path string, parent *BasicActor) (Actor, error) {
+	actor := props.newActor()
+	if b := asBasicActor(actor); b != nil {
+		b.name = path[strings.LastIndex(path, "/")+1:]
+		b.path = path
+		b.system = s
+		b.SetDeadLetters(s.deadLetters)
+	}
+
+	if err := s.registry.RegisterActorAs(path, actor); err != nil {
+		return nil, err
+	}
+	supervisor := s.guardian
+	if b := asBasicActor(actor); b != nil && parent != nil {
+		parent.addChild(b)
+		supervisor = parent.childSupervisor()
+	}
+	supervisor.superviseActor(actor, props)
+	return actor, nil
+}
+
+// Lookup returns the actor registered at path
+func (s *ActorSystem) Lookup(path string) (Actor, bool) {
+	return s.registry.GetActor(path)
+}
+
+// ActorSelection returns the actors whose path matches pattern. Wildcards
+// follow path.Match, so "/user/orders/*" selects the direct children of
+// /user/orders.
+func (s *ActorSystem) ActorSelection(pattern string) *ActorSelection {
+	return &ActorSelection{
+		actors: s.registry.FindActors(pattern),
+	}
+}
+
+// Stop stops the actor and removes it from the system
+func (s *ActorSystem) Stop(actor Actor) {
+	supervisor := s.guardian
+	if b := asBasicActor(actor); b != nil {
+		if b.path != "" {
+			s.registry.UnregisterActor(b.path)
+		}
+		if parent := b.getParent(); parent != nil {
+			if children := parent.currentSupervisor(); children != nil {
+				supervisor = children
+			}
+		}
+	}
+	supervisor.StopActor(actor)
+}
+
+// Shutdown stops every actor in the system
+func (s *ActorSystem) Shutdown() {
+	s.scheduler.Stop()
+	s.guardian.Stop()
+}
+
+// Wait blocks until the system is shut down
+func (s *ActorSystem) Wait() {
+	s.guardian.Wait()
+}
+
+// ActorSelection is the set of actors matched by a path pattern
+type ActorSelection struct {
+	actors []Actor
+}
+
+func (sel *ActorSelection) Actors() []Actor {
+	return sel.actors
+}
+
+// SendMessage sends msg to every selected actor
+func (sel *ActorSelection) SendMessage(msg interface{}) {
+	for _, actor := range sel.actors {
+		actor.SendMessage(msg)
+	}
+}
+
+func validateActorName(name string) error {
+	if name == "" {
+		return fmt.Errorf("actor name must not be empty")
+	}
+	for _, segment := range strings.Split(name, "/") {
+		if segment == "" {
+			return fmt.Errorf("invalid actor name %q: empty path segment", name)
+		}
+		if strings.ContainsAny(segment, "*?[]\\") {
+			return fmt.Errorf("invalid actor name %q: wildcard characters are not allowed", name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,136 @@
+package core
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+type orderPlaced struct {
+	OrderID string
+}
+
+// Test suite for ActorSystem
+func TestActorSystem(t *testing.T) {
+
+	t.Run("TestActorOfRegistersPath", func(t *testing.T) {
+		// Arrange
+		system := NewActorSystem(context.Background(), "test-system")
+		defer system.Shutdown()
+
+		// Act
+		actor, err := system.ActorOf(PropsFromFunc(func(result *ActorResult) *ActorResult {
+			return &ActorResult{}
+		}), "orders/worker-3")
+
+		// Assert
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if actor.GetName() != "worker-3" {
+			t.Errorf("expected name 'worker-3', got '%s'", actor.GetName())
+		}
+		found, exists := system.Lookup("/user/orders/worker-3")
+		if !exists || found.GetID() != actor.GetID() {
+			t.Errorf("expected actor to be registered at its path")
+		}
+	})
+
+	t.Run("TestActorOfRejectsDuplicateAndInvalidNames", func(t *testing.T) {
+		// Arrange
+		system := NewActorSystem(context.Background(), "test-system")
+		defer system.Shutdown()
+		props := PropsFromFunc(func(result *ActorResult) *ActorResult {
+			return &ActorResult{}
+		})
+
+		// Act
+		_, err := system.ActorOf(props, "worker")
+		_, errDuplicate := system.ActorOf(props, "worker")
+		_, errEmpty := system.ActorOf(props, "orders//worker")
+		_, errWildcard := system.ActorOf(props, "orders/*")
+
+		// Assert
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if errDuplicate == nil || errEmpty == nil || errWildcard == nil {
+			t.Errorf("expected duplicate, empty and wildcard names to be rejected")
+		}
+	})
+
+	t.Run("TestActorSelection", func(t *testing.T) {
+		// Arrange
+		system := NewActorSystem(context.Background(), "test-system")
+		defer system.Shutdown()
+
+		var wg sync.WaitGroup
+		wg.Add(3)
+		props := PropsFromFunc(func(result *ActorResult) *ActorResult {
+			wg.Done()
+			return &ActorResult{}
+		})
+		system.ActorOf(props, "orders/worker-1")
+		system.ActorOf(props, "orders/worker-2")
+		system.ActorOf(props, "orders/worker-3")
+		system.ActorOf(props, "billing/worker-1")
+
+		// Act
+		selection := system.ActorSelection("/user/orders/*")
+		selection.SendMessage("work")
+
+		// Assert
+		if len(selection.Actors()) != 3 {
+			t.Errorf("expected 3 selected actors, got %d", len(selection.Actors()))
+		}
+		wg.Wait()
+	})
+
+	t.Run("TestStopRemovesActor", func(t *testing.T) {
+		// Arrange
+		system := NewActorSystem(context.Background(), "test-system")
+		defer system.Shutdown()
+		actor, _ := system.ActorOf(PropsFromFunc(func(result *ActorResult) *ActorResult {
+			return &ActorResult{}
+		}), "worker")
+
+		// Act
+		system.Stop(actor)
+
+		// Assert
+		if _, exists := system.Lookup("/user/worker"); exists {
+			t.Errorf("expected stopped actor to be removed from the system")
+		}
+	})
+
+	t.Run("TestEventStream", func(t *testing.T) {
+		// Arrange
+		system := NewActorSystem(context.Background(), "test-system")
+		defer system.Shutdown()
+
+		received := make(chan interface{}, 1)
+		listener, _ := system.ActorOf(PropsFromFunc(func(result *ActorResult) *ActorResult {
+			received <- result.Message
+			return &ActorResult{}
+		}), "listener")
+		system.EventStream().Subscribe(listener, orderPlaced{})
+
+		// Act
+		ignored := system.EventStream().Publish("not an order event")
+		delivered := system.EventStream().Publish(orderPlaced{OrderID: "42"})
+
+		// Assert
+		if ignored || !delivered {
+			t.Errorf("expected only subscribed event types to be delivered")
+		}
+		select {
+		case msg := <-received:
+			if msg.(orderPlaced).OrderID != "42" {
+				t.Errorf("expected order 42, got %v", msg)
+			}
+		case <-time.After(time.Second):
+			t.Errorf("expected event to be delivered")
+		}
+	})
+}
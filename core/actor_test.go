@@ -237,7 +237,7 @@ func TestActor(t *testing.T) {
 		parentWg.Wait()
 		childWg.Wait() // Ensure both parent and child actors have stopped
 
-		if len(parentActor.mailbox) != 0 || len(childActor.mailbox) != 0 {
+		if parentActor.mailbox.Len() != 0 || childActor.mailbox.Len() != 0 {
 			t.Errorf("expected both actors to stop and have empty mailboxes")
 		}
 	})
@@ -294,4 +294,72 @@ func TestActor(t *testing.T) {
 		actor.Stop()
 	})
 
+	t.Run("TestActorRecoversPanic", func(t *testing.T) {
+		// Arrange
+		failureChannel := make(chan *ActorResult, 1)
+		actor := NewBasicActor("test-actor")
+		actor.ReceiveFunc = func(result *ActorResult) *ActorResult {
+			if result.Message == "panic" {
+				panic("something went wrong")
+			}
+			return &ActorResult{}
+		}
+		actor.SetPanicAction(ACTOR_RETRY)
+		actor.SetFailureChannel(failureChannel)
+		actor.Start()
+		defer actor.Stop()
+
+		// Act
+		actor.SendMessage("panic")
+
+		// Assert
+		select {
+		case failure := <-failureChannel:
+			panicErr, ok := failure.Error.(*PanicError)
+			if !ok {
+				t.Fatalf("expected a PanicError, got %v", failure.Error)
+			}
+			if panicErr.Value != "something went wrong" || len(panicErr.Stack) == 0 {
+				t.Errorf("expected panic value and stack trace, got %v", panicErr)
+			}
+			if failure.Message != "panic" {
+				t.Errorf("expected offending message, got %v", failure.Message)
+			}
+			if failure.Action != ACTOR_RETRY || failure.ID != actor.GetID() {
+				t.Errorf("expected configured action and actor ID in the failure")
+			}
+		case <-time.After(time.Second):
+			t.Errorf("expected the panic to be sent to the failure channel")
+		}
+	})
+
+	t.Run("TestSupervisorRestartsPanickingActor", func(t *testing.T) {
+		// Arrange
+		received := make(chan interface{}, 10)
+		actor := NewBasicActor("test-actor")
+		actor.ReceiveFunc = func(result *ActorResult) *ActorResult {
+			if result.Message == "panic" {
+				panic("something went wrong")
+			}
+			received <- result.Message
+			return &ActorResult{}
+		}
+		supervisor := NewSupervisor(context.Background())
+		defer supervisor.Stop()
+		supervisor.SuperviseActor(actor)
+
+		// Act
+		actor.SendMessage("panic")
+		actor.SendMessage("after panic")
+
+		// Assert
+		select {
+		case msg := <-received:
+			if msg != "after panic" {
+				t.Errorf("expected 'after panic', got %v", msg)
+			}
+		case <-time.After(time.Second):
+			t.Errorf("expected actor to keep processing after a panic")
+		}
+	})
 }
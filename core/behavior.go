@@ -0,0 +1,43 @@
+package core
+
+// Behavior handles messages in place of an actor's ReceiveFunc, letting
+// actors that act as state machines switch handlers between messages
+type Behavior func(result *ActorResult) *ActorResult
+
+// Become replaces the current behavior of the actor processing this message.
+// The switch takes effect on the next message. It is a no-op on results not
+// passed to a receive function.
+func (r *ActorResult) Become(behavior Behavior) {
+	if a := r.actor; a != nil {
+		if n := len(a.behaviors); n > 0 {
+			a.behaviors[n-1] = behavior
+			return
+		}
+		a.behaviors = append(a.behaviors, behavior)
+	}
+}
+
+// BecomeStacked pushes behavior on top of the current one, so Unbecome
+// returns to it
+func (r *ActorResult) BecomeStacked(behavior Behavior) {
+	if a := r.actor; a != nil {
+		a.behaviors = append(a.behaviors, behavior)
+	}
+}
+
+// Unbecome returns to the previous behavior, ending with the ReceiveFunc
+func (r *ActorResult) Unbecome() {
+	if a := r.actor; a != nil && len(a.behaviors) > 0 {
+		a.behaviors[len(a.behaviors)-1] = nil
+		a.behaviors = a.behaviors[:len(a.behaviors)-1]
+	}
+}
+
+// receiveFunc returns the current behavior. The behavior stack is only used
+// from the run loop, so it needs no locking.
+func (a *BasicActor) receiveFunc() Behavior {
+	if n := len(a.behaviors); n > 0 {
+		return a.behaviors[n-1]
+	}
+	return a.ReceiveFunc
+}
@@ -0,0 +1,132 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func expectReplies(t *testing.T, actor Actor, replies map[string]interface{}, order ...string) {
+	t.Helper()
+	for _, msg := range order {
+		future, err := Ask(context.Background(), actor, msg)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		reply, err := future.Await(ctx)
+		cancel()
+		if err != nil || reply != replies[msg] {
+			t.Errorf("expected %v for %q, got %v (%v)", replies[msg], msg, reply, err)
+		}
+	}
+}
+
+// Test suite for Become and Unbecome
+func TestBehaviors(t *testing.T) {
+
+	t.Run("TestBecomeTakesEffectOnNextMessage", func(t *testing.T) {
+		// Arrange
+		actor := NewBasicActor("door")
+		var closed, open Behavior
+		closed = func(result *ActorResult) *ActorResult {
+			if result.Message == "open" {
+				result.Become(open)
+			}
+			result.Reply("closed")
+			return &ActorResult{}
+		}
+		open = func(result *ActorResult) *ActorResult {
+			if result.Message == "close" {
+				result.Become(closed)
+			}
+			result.Reply("open")
+			return &ActorResult{}
+		}
+		actor.ReceiveFunc = closed
+		actor.Start()
+		defer actor.Stop()
+
+		// Act
+		future, _ := Ask(context.Background(), actor, "open")
+		reply, _ := future.Await(context.Background())
+
+		// Assert
+		if reply != "closed" {
+			t.Errorf("expected the message switching behavior to be handled by the old one, got %v", reply)
+		}
+		future, _ = Ask(context.Background(), actor, "knock")
+		reply, _ = future.Await(context.Background())
+		if reply != "open" {
+			t.Errorf("expected the next message to be handled by the new behavior, got %v", reply)
+		}
+	})
+
+	t.Run("TestBecomeStackedAndUnbecome", func(t *testing.T) {
+		// Arrange
+		actor := NewBasicActor("stacked")
+		busy := func(result *ActorResult) *ActorResult {
+			if result.Message == "done" {
+				result.Unbecome()
+			}
+			result.Reply("busy")
+			return &ActorResult{}
+		}
+		actor.ReceiveFunc = func(result *ActorResult) *ActorResult {
+			if result.Message == "work" {
+				result.BecomeStacked(busy)
+			}
+			if result.Message == "done" {
+				result.Unbecome()
+			}
+			result.Reply("idle")
+			return &ActorResult{}
+		}
+		actor.Start()
+		defer actor.Stop()
+
+		// Act & Assert
+		expectReplies(t, actor, map[string]interface{}{"work": "idle", "ping": "busy", "done": "busy", "again": "idle"},
+			"work", "ping", "done", "again")
+		// Unbecome without a stacked behavior keeps the ReceiveFunc
+		expectReplies(t, actor, map[string]interface{}{"done": "idle", "ping": "idle"}, "done", "ping")
+	})
+
+	t.Run("TestRestartResetsBehavior", func(t *testing.T) {
+		// Arrange
+		supervisor := NewSupervisor(context.Background())
+		defer supervisor.Stop()
+		actor := NewBasicActor("resettable")
+		broken := func(result *ActorResult) *ActorResult {
+			return &ActorResult{Error: errors.New("broken state")}
+		}
+		actor.ReceiveFunc = func(result *ActorResult) *ActorResult {
+			if result.Message == "break" {
+				result.Become(broken)
+			}
+			result.Reply("initial")
+			return &ActorResult{}
+		}
+		supervisor.SuperviseActor(actor)
+
+		// Act
+		expectReplies(t, actor, map[string]interface{}{"break": "initial"}, "break")
+		actor.SendMessage("fail")
+
+		// Assert
+		deadline := time.Now().Add(time.Second)
+		for {
+			future, _ := Ask(context.Background(), actor, "ping")
+			ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+			reply, err := future.Await(ctx)
+			cancel()
+			if err == nil && reply == "initial" {
+				return
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("expected the restarted actor to use its initial behavior, got %v (%v)", reply, err)
+			}
+		}
+	})
+}
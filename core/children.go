@@ -0,0 +1,158 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// childFailure escalates a failure the actor's child supervisor could not
+// handle to the actor itself, which then fails towards its own supervisor
+type childFailure struct {
+	result *ActorResult
+}
+
+func (*childFailure) SystemMessage() {}
+
+// SetChildSupervision configures the supervisor of the children spawned
+// through the actor's context, e.g. its strategy, restart intensity or
+// backoff. Failures it cannot handle are escalated to the actor, which fails
+// in turn.
+func (a *BasicActor) SetChildSupervision(configure func(supervisor *Supervisor)) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.supervision = configure
+}
+
+// Children returns the live actors spawned by this actor
+func (a *BasicActor) Children() []Actor {
+	a.mu.Lock()
+	spawned := append([]*BasicActor(nil), a.children...)
+	a.mu.Unlock()
+	children := make([]Actor, 0, len(spawned))
+	for _, child := range spawned {
+		children = append(children, child.outer())
+	}
+	return children
+}
+
+// spawnChild creates an actor at <a's path>/<name> in a's actor system
+func (a *BasicActor) spawnChild(props *Props, name string) (Actor, error) {
+	if a.system == nil {
+		return nil, ErrNoActorSystem
+	}
+	if strings.Contains(name, "/") {
+		return nil, fmt.Errorf("invalid child name %q: must be a single path segment", name)
+	}
+	if err := validateActorName(name); err != nil {
+		return nil, err
+	}
+	return a.system.spawn(props, a.path+"/"+name, a)
+}
+
+// addChild records child as spawned by a
+func (a *BasicActor) addChild(child *BasicActor) {
+	child.setParent(a)
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.children = append(a.children, child)
+}
+
+// replaceChild swaps a restarted child for its next incarnation
+func (a *BasicActor) replaceChild(child *BasicActor) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for i, c := range a.children {
+		if c.id == child.id {
+			a.children[i] = child
+			return
+		}
+	}
+}
+
+func (a *BasicActor) getParent() *BasicActor {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.parent
+}
+
+func (a *BasicActor) setParent(parent *BasicActor) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.parent = parent
+}
+
+// removeChild forgets a child that stopped for good
+func (a *BasicActor) removeChild(child *BasicActor) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for i, c := range a.children {
+		if c.id == child.id {
+			a.children = append(a.children[:i:i], a.children[i+1:]...)
+			return
+		}
+	}
+}
+
+// childSupervisor returns the supervisor of the actor's children, creating
+// it on the first spawn
+func (a *BasicActor) childSupervisor() *Supervisor {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.supervisor != nil {
+		return a.supervisor
+	}
+
+	ctx := a.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	supervisor := NewSupervisor(ctx)
+	if a.supervision != nil {
+		a.supervision(supervisor)
+	}
+	handler := supervisor.onTerminalFailure
+	supervisor.SetTerminalFailureHandler(func(result *SupervisorActorResult) {
+		if handler != nil {
+			handler(result)
+		}
+		a.tell(&childFailure{result: result.Result})
+	})
+	a.supervisor = supervisor
+	return supervisor
+}
+
+// currentSupervisor returns the supervisor of the actor's children, nil if it
+// has not spawned any
+func (a *BasicActor) currentSupervisor() *Supervisor {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.supervisor
+}
+
+// stopChildren stops the children and waits for them, so they are gone
+// before the actor's own stop hooks run
+func (a *BasicActor) stopChildren() {
+	a.mu.Lock()
+	supervisor := a.supervisor
+	children := a.children
+	a.supervisor = nil
+	a.children = nil
+	a.mu.Unlock()
+	if supervisor == nil {
+		return
+	}
+
+	supervisor.Stop()
+	for _, child := range children {
+		child.awaitTermination()
+	}
+}
+
+// escalatedFailure turns a childFailure into the actor's own failure
+func (f *childFailure) escalatedFailure() *ActorResult {
+	return &ActorResult{
+		Error:  fmt.Errorf("child %s failed: %w", f.result.name, f.result.Error),
+		Action: ACTOR_RESTART,
+	}
+}
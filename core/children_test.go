@@ -0,0 +1,195 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+var errChildFailed = errors.New("child failed")
+
+type spawnRequest struct {
+	name  string
+	props *Props
+}
+
+// familyActor records its start and stop hooks, spawns children on a
+// spawnRequest and fails on "fail"
+type familyActor struct {
+	*BasicActor
+	label  string
+	events chan string
+}
+
+func newFamilyProps(label string, events chan string, configure func(a *familyActor)) *Props {
+	return PropsFromProducer(func() Actor {
+		a := &familyActor{BasicActor: NewBasicActor(label), label: label, events: events}
+		a.ReceiveFunc = func(result *ActorResult) *ActorResult {
+			switch msg := result.Message.(type) {
+			case spawnRequest:
+				child, err := result.Context().Spawn(msg.props, msg.name)
+				if err != nil {
+					result.Reply(err)
+				} else {
+					result.Reply(child)
+				}
+			case string:
+				if msg == "fail" {
+					return &ActorResult{Error: errChildFailed}
+				}
+				if msg == "fail for good" {
+					return &ActorResult{Error: errChildFailed, Action: ACTOR_FAIL}
+				}
+			}
+			return &ActorResult{}
+		}
+		if configure != nil {
+			configure(a)
+		}
+		return a
+	})
+}
+
+func (a *familyActor) PreStart() error {
+	a.events <- "start " + a.label
+	return nil
+}
+
+func (a *familyActor) PostStop() {
+	a.events <- "stop " + a.label
+}
+
+func spawnChild(t *testing.T, parent Actor, name string, props *Props) Actor {
+	t.Helper()
+	child, ok := askWithTimeout(t, parent, spawnRequest{name: name, props: props}).(Actor)
+	if !ok {
+		t.Fatalf("expected a child actor")
+	}
+	return child
+}
+
+// Test suite for children spawned from an actor's context
+func TestChildActors(t *testing.T) {
+
+	t.Run("TestParentRestartsFailedChild", func(t *testing.T) {
+		// Arrange
+		events := make(chan string, 20)
+		system := NewActorSystem(context.Background(), "test-system")
+		defer system.Shutdown()
+		parent, _ := system.ActorOf(newFamilyProps("parent", events, nil), "parent")
+		expectEvents(t, events, "start parent")
+		child := spawnChild(t, parent, "a", newFamilyProps("a", events, nil))
+		expectEvents(t, events, "start a")
+
+		// Act
+		child.SendMessage("fail")
+
+		// Assert
+		expectEvents(t, events, "stop a", "start a")
+		if found, _ := system.Lookup("/user/parent/a"); found == nil || found.GetID() != child.GetID() {
+			t.Errorf("expected the restarted child to stay registered")
+		}
+		select {
+		case event := <-events:
+			t.Errorf("expected the parent to be unaffected, got %q", event)
+		case <-time.After(50 * time.Millisecond):
+		}
+	})
+
+	t.Run("TestParentStrategy", func(t *testing.T) {
+		// Arrange
+		events := make(chan string, 20)
+		system := NewActorSystem(context.Background(), "test-system")
+		defer system.Shutdown()
+		parent, _ := system.ActorOf(newFamilyProps("parent", events, func(a *familyActor) {
+			a.SetChildSupervision(func(supervisor *Supervisor) {
+				supervisor.SetStrategy(ONE_FOR_ALL)
+			})
+		}), "parent")
+		expectEvents(t, events, "start parent")
+		first := spawnChild(t, parent, "a", newFamilyProps("a", events, nil))
+		spawnChild(t, parent, "b", newFamilyProps("b", events, nil))
+		expectEvents(t, events, "start a", "start b")
+
+		// Act
+		first.SendMessage("fail")
+
+		// Assert
+		expectEvents(t, events, "stop b", "stop a")
+		// Restarted children start concurrently
+		started := map[string]bool{}
+		for i := 0; i < 2; i++ {
+			select {
+			case event := <-events:
+				started[event] = true
+			case <-time.After(time.Second):
+				t.Fatalf("expected both children to be restarted")
+			}
+		}
+		if !started["start a"] || !started["start b"] {
+			t.Errorf("expected both children to be restarted, got %v", started)
+		}
+	})
+
+	t.Run("TestChildrenStopBeforeParent", func(t *testing.T) {
+		// Arrange
+		events := make(chan string, 20)
+		system := NewActorSystem(context.Background(), "test-system")
+		defer system.Shutdown()
+		parent, _ := system.ActorOf(newFamilyProps("parent", events, nil), "parent")
+		child := spawnChild(t, parent, "a", newFamilyProps("a", events, nil))
+		grandchild := spawnChild(t, child, "b", newFamilyProps("b", events, nil))
+		expectEvents(t, events, "start parent", "start a", "start b")
+
+		// Act
+		system.Stop(parent)
+
+		// Assert
+		expectEvents(t, events, "stop b", "stop a", "stop parent")
+		path := grandchild.(*familyActor).GetPath()
+		if path != "/user/parent/a/b" {
+			t.Errorf("unexpected path %s", path)
+		}
+		for _, path := range []string{"/user/parent", "/user/parent/a", path} {
+			if _, exists := system.Lookup(path); exists {
+				t.Errorf("expected %s to be unregistered", path)
+			}
+		}
+	})
+
+	t.Run("TestFailureEscalatesToParent", func(t *testing.T) {
+		// Arrange
+		events := make(chan string, 20)
+		failures := make(chan error, 1)
+		system := NewActorSystem(context.Background(), "test-system")
+		defer system.Shutdown()
+		parent, _ := system.ActorOf(newFamilyProps("parent", events, func(a *familyActor) {
+			a.SetChildSupervision(func(supervisor *Supervisor) {
+				supervisor.SetTerminalFailureHandler(func(result *SupervisorActorResult) {
+					failures <- result.Result.Error
+				})
+			})
+		}), "parent")
+		child := spawnChild(t, parent, "a", newFamilyProps("a", events, nil))
+		expectEvents(t, events, "start parent", "start a")
+
+		// Act
+		child.SendMessage("fail for good")
+
+		// Assert
+		select {
+		case err := <-failures:
+			if !errors.Is(err, errChildFailed) {
+				t.Errorf("expected the child's failure, got %v", err)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("expected the configured terminal failure handler to be called")
+		}
+		expectEvents(t, events, "stop a", "stop parent", "start parent")
+		restarted, _ := system.Lookup("/user/parent")
+		if restarted.GetID() != parent.GetID() || len(asBasicActor(restarted).Children()) != 0 {
+			t.Errorf("expected the restarted parent to start without children")
+		}
+	})
+}
@@ -6,8 +6,24 @@ const (
 	ACTOR_FAIL
 )
 
+// Directives a parent supervisor applies to a failed sub-supervisor
 const (
+	// SUPERVISOR_RESTART restarts the whole subtree
 	SUPERVISOR_RESTART = iota
+	// SUPERVISOR_FAIL escalates the failure to the next layer
 	SUPERVISOR_FAIL
+	// SUPERVISOR_IGNORE resumes the subtree as if nothing happened
 	SUPERVISOR_IGNORE
+	// SUPERVISOR_STOP stops the whole subtree
+	SUPERVISOR_STOP
+)
+
+// Supervision strategies deciding which children are restarted on failure
+const (
+	// ONE_FOR_ONE restarts only the failed actor
+	ONE_FOR_ONE = iota
+	// ONE_FOR_ALL restarts every child of the supervisor
+	ONE_FOR_ALL
+	// REST_FOR_ONE restarts the failed actor and the ones started after it
+	REST_FOR_ONE
 )
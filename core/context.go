@@ -0,0 +1,180 @@
+package core
+
+import (
+	"errors"
+	"log"
+	"os"
+	"time"
+)
+
+// ErrNoActorSystem is returned when an operation needs the actor to have
+// been created through an ActorSystem
+var ErrNoActorSystem = errors.New("actor does not belong to an actor system")
+
+// Logger is the logging interface handed to actors, satisfied by *log.Logger
+type Logger interface {
+	Printf(format string, v ...interface{})
+}
+
+var defaultLogger Logger = log.New(os.Stdout, "", 0)
+
+// ActorContext gives a receive function access to the actor processing the
+// message and to its surroundings, so handlers need no package-level
+// registries or brokers
+type ActorContext interface {
+	// Self returns the actor processing the message
+	Self() Actor
+	// Sender returns the actor that sent the message, nil if unknown
+	Sender() Actor
+	// Message returns the message being processed
+	Message() interface{}
+	// Reply answers the message, see ActorResult.Reply
+	Reply(msg interface{}) error
+	// Parent returns the actor that spawned this one, nil for top-level actors
+	Parent() Actor
+	// Children returns the live actors spawned by this one
+	Children() []Actor
+	// Spawn creates a child actor at <self path>/<name>
+	Spawn(props *Props, name string) (Actor, error)
+	// Watch delivers Terminated to this actor when actor stops
+	Watch(actor Actor) error
+	// Unwatch cancels Watch
+	Unwatch(actor Actor)
+	// Stop stops actor, removing it from the actor system if it belongs to one
+	Stop(actor Actor)
+	// StartTimer sends msg to this actor every interval until cancelled
+	StartTimer(key string, msg interface{}, interval time.Duration)
+	// StartSingleTimer sends msg to this actor once after delay
+	StartSingleTimer(key string, msg interface{}, delay time.Duration)
+	// CancelTimer stops the timer started under key
+	CancelTimer(key string)
+	// SetReceiveTimeout delivers ReceiveTimeout after timeout without messages
+	SetReceiveTimeout(timeout time.Duration)
+	// Become, BecomeStacked and Unbecome switch behaviors, see ActorResult
+	Become(behavior Behavior)
+	BecomeStacked(behavior Behavior)
+	Unbecome()
+	// Stash and UnstashAll defer messages, see BasicActor.Stash
+	Stash() error
+	UnstashAll()
+	// System returns the actor system, nil for actors created without one
+	System() *ActorSystem
+	// Broker returns the actor system's broker, nil without an actor system
+	Broker() MessageBroker
+	// Logger returns the actor system's logger
+	Logger() Logger
+}
+
+// Context returns the context of the message being processed. It is only
+// valid inside the receive function.
+func (r *ActorResult) Context() ActorContext {
+	return &actorContext{result: r, actor: r.actor}
+}
+
+type actorContext struct {
+	result *ActorResult
+	actor  *BasicActor
+}
+
+func (c *actorContext) Self() Actor {
+	return c.actor.outer()
+}
+
+func (c *actorContext) Sender() Actor {
+	return c.result.Sender
+}
+
+func (c *actorContext) Message() interface{} {
+	return c.result.Message
+}
+
+func (c *actorContext) Reply(msg interface{}) error {
+	return c.result.Reply(msg)
+}
+
+func (c *actorContext) Parent() Actor {
+	if parent := c.actor.getParent(); parent != nil {
+		return parent.outer()
+	}
+	return nil
+}
+
+func (c *actorContext) Children() []Actor {
+	return c.actor.Children()
+}
+
+func (c *actorContext) Spawn(props *Props, name string) (Actor, error) {
+	return c.actor.spawnChild(props, name)
+}
+
+func (c *actorContext) Watch(actor Actor) error {
+	return Watch(c.Self(), actor)
+}
+
+func (c *actorContext) Unwatch(actor Actor) {
+	Unwatch(c.Self(), actor)
+}
+
+func (c *actorContext) Stop(actor Actor) {
+	if system := c.actor.system; system != nil {
+		if b := asBasicActor(actor); b != nil && b.system == system {
+			system.Stop(actor)
+			return
+		}
+	}
+	actor.Stop()
+}
+
+func (c *actorContext) StartTimer(key string, msg interface{}, interval time.Duration) {
+	c.actor.StartTimer(key, msg, interval)
+}
+
+func (c *actorContext) StartSingleTimer(key string, msg interface{}, delay time.Duration) {
+	c.actor.StartSingleTimer(key, msg, delay)
+}
+
+func (c *actorContext) CancelTimer(key string) {
+	c.actor.CancelTimer(key)
+}
+
+func (c *actorContext) SetReceiveTimeout(timeout time.Duration) {
+	c.actor.SetReceiveTimeout(timeout)
+}
+
+func (c *actorContext) Become(behavior Behavior) {
+	c.result.Become(behavior)
+}
+
+func (c *actorContext) BecomeStacked(behavior Behavior) {
+	c.result.BecomeStacked(behavior)
+}
+
+func (c *actorContext) Unbecome() {
+	c.result.Unbecome()
+}
+
+func (c *actorContext) Stash() error {
+	return c.actor.Stash()
+}
+
+func (c *actorContext) UnstashAll() {
+	c.actor.UnstashAll()
+}
+
+func (c *actorContext) System() *ActorSystem {
+	return c.actor.system
+}
+
+func (c *actorContext) Broker() MessageBroker {
+	if system := c.actor.system; system != nil {
+		return system.Broker()
+	}
+	return nil
+}
+
+func (c *actorContext) Logger() Logger {
+	if system := c.actor.system; system != nil {
+		return system.Logger()
+	}
+	return defaultLogger
+}
@@ -0,0 +1,225 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+type recordingLogger struct {
+	mu      sync.Mutex
+	entries []string
+}
+
+func (l *recordingLogger) Printf(format string, v ...interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.entries = append(l.entries, fmt.Sprintf(format, v...))
+}
+
+func (l *recordingLogger) Entries() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]string(nil), l.entries...)
+}
+
+func askWithTimeout(t *testing.T, actor Actor, msg interface{}) interface{} {
+	t.Helper()
+	future, err := Ask(context.Background(), actor, msg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	reply, err := future.Await(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return reply
+}
+
+// Test suite for ActorContext
+func TestActorContext(t *testing.T) {
+
+	t.Run("TestSelfSenderAndMessage", func(t *testing.T) {
+		// Arrange
+		received := make(chan ActorContext, 1)
+		actor := NewBasicActor("context-actor")
+		actor.ReceiveFunc = func(result *ActorResult) *ActorResult {
+			received <- result.Context()
+			return &ActorResult{}
+		}
+		sender := NewBasicActor("sender")
+		actor.Start()
+		defer actor.Stop()
+
+		// Act
+		Tell(actor, "hello", sender)
+
+		// Assert
+		ctx := <-received
+		if ctx.Self() != actor || ctx.Sender() != sender || ctx.Message() != "hello" {
+			t.Errorf("expected self, sender and message to be exposed, got %v %v %v", ctx.Self(), ctx.Sender(), ctx.Message())
+		}
+		if ctx.Parent() != nil || ctx.System() != nil || ctx.Broker() != nil {
+			t.Errorf("expected no parent, system or broker for a standalone actor")
+		}
+		if _, err := ctx.Spawn(PropsFromFunc(nil), "child"); !errors.Is(err, ErrNoActorSystem) {
+			t.Errorf("expected ErrNoActorSystem, got %v", err)
+		}
+	})
+
+	t.Run("TestSpawnChildren", func(t *testing.T) {
+		// Arrange
+		system := NewActorSystem(context.Background(), "test-system")
+		defer system.Shutdown()
+		childProps := PropsFromFunc(func(result *ActorResult) *ActorResult {
+			ctx := result.Context()
+			ctx.Reply(ctx.Parent())
+			return &ActorResult{}
+		})
+		parent, _ := system.ActorOf(PropsFromFunc(func(result *ActorResult) *ActorResult {
+			ctx := result.Context()
+			switch result.Message {
+			case "spawn":
+				child, err := ctx.Spawn(childProps, "child")
+				if err != nil {
+					ctx.Reply(err)
+					return &ActorResult{}
+				}
+				ctx.Reply(child)
+			case "stop":
+				ctx.Stop(ctx.Children()[0])
+				ctx.Reply(nil)
+			case "children":
+				ctx.Reply(len(ctx.Children()))
+			}
+			return &ActorResult{}
+		}), "parent")
+
+		// Act
+		child, ok := askWithTimeout(t, parent, "spawn").(Actor)
+
+		// Assert
+		if !ok {
+			t.Fatalf("expected a child actor")
+		}
+		if found, exists := system.Lookup("/user/parent/child"); !exists || found.GetID() != child.GetID() {
+			t.Errorf("expected the child to be registered under its parent's path")
+		}
+		if reported := askWithTimeout(t, child, "parent?"); reported != parent {
+			t.Errorf("expected the child's parent to be %v, got %v", parent, reported)
+		}
+		if count := askWithTimeout(t, parent, "children"); count != 1 {
+			t.Errorf("expected 1 child, got %v", count)
+		}
+		askWithTimeout(t, parent, "stop")
+		asBasicActor(child).awaitTermination()
+		if count := askWithTimeout(t, parent, "children"); count != 0 {
+			t.Errorf("expected the stopped child to be removed, got %v children", count)
+		}
+		if _, exists := system.Lookup("/user/parent/child"); exists {
+			t.Errorf("expected the stopped child to be unregistered")
+		}
+	})
+
+	t.Run("TestBrokerAndLogger", func(t *testing.T) {
+		// Arrange
+		system := NewActorSystem(context.Background(), "test-system")
+		defer system.Shutdown()
+		logger := &recordingLogger{}
+		system.SetLogger(logger)
+		subscriber := make(chan interface{}, 1)
+		listener := NewBasicActor("listener")
+		listener.ReceiveFunc = func(result *ActorResult) *ActorResult {
+			subscriber <- result.Message
+			return &ActorResult{}
+		}
+		listener.Start()
+		defer listener.Stop()
+		system.Broker().Subscribe("news", listener)
+		publisher, _ := system.ActorOf(PropsFromFunc(func(result *ActorResult) *ActorResult {
+			ctx := result.Context()
+			ctx.Logger().Printf("publishing %v", ctx.Message())
+			ctx.Broker().Publish("news", ctx.Message())
+			return &ActorResult{}
+		}), "publisher")
+
+		// Act
+		publisher.SendMessage("extra")
+
+		// Assert
+		select {
+		case msg := <-subscriber:
+			if msg != "extra" {
+				t.Errorf("expected 'extra', got %v", msg)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("expected the message to be published through the system broker")
+		}
+		if entries := logger.Entries(); len(entries) != 1 || entries[0] != "publishing extra" {
+			t.Errorf("expected the system logger to be used, got %v", entries)
+		}
+	})
+
+	t.Run("TestTimers", func(t *testing.T) {
+		// Arrange
+		ticks := make(chan interface{}, 100)
+		actor := NewBasicActor("ticking")
+		actor.ReceiveFunc = func(result *ActorResult) *ActorResult {
+			ctx := result.Context()
+			switch result.Message {
+			case "start":
+				ctx.StartTimer("tick", "tick", 5*time.Millisecond)
+			case "cancel":
+				ctx.CancelTimer("tick")
+				ctx.Reply(nil)
+			default:
+				ticks <- result.Message
+			}
+			return &ActorResult{}
+		}
+		actor.Start()
+		defer actor.Stop()
+
+		// Act
+		actor.SendMessage("start")
+
+		// Assert
+		expectMessages(t, ticks, "tick", "tick")
+		askWithTimeout(t, actor, "cancel")
+		time.Sleep(20 * time.Millisecond)
+		for len(ticks) > 0 {
+			<-ticks
+		}
+		select {
+		case <-ticks:
+			t.Errorf("expected no ticks after CancelTimer")
+		case <-time.After(30 * time.Millisecond):
+		}
+	})
+
+	t.Run("TestTimersCancelledOnStop", func(t *testing.T) {
+		// Arrange
+		actor := NewBasicActor("stopping")
+		actor.ReceiveFunc = func(result *ActorResult) *ActorResult {
+			return &ActorResult{}
+		}
+		actor.StartTimer("tick", "tick", 5*time.Millisecond)
+		actor.Start()
+
+		// Act
+		actor.Stop()
+		actor.awaitTermination()
+
+		// Assert
+		actor.mu.Lock()
+		defer actor.mu.Unlock()
+		if len(actor.timers) != 0 {
+			t.Errorf("expected timers to be cancelled on stop, got %d", len(actor.timers))
+		}
+	})
+}
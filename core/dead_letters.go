@@ -0,0 +1,191 @@
+package core
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrActorStopped is returned when sending to an actor that has stopped
+	ErrActorStopped = errors.New("actor stopped")
+	// ErrActorRestarted is the reason of the queued and stashed messages an
+	// actor discards when it restarts without carrying its mailbox over
+	ErrActorRestarted = errors.New("actor restarted")
+	// ErrNoSubscribers is returned when publishing to a topic nobody listens to
+	ErrNoSubscribers = errors.New("no subscribers")
+)
+
+// DeadLetter is a message that could not be delivered. Recipient is nil for
+// messages published to a topic, which is set instead.
+type DeadLetter struct {
+	Message   interface{}
+	Sender    Actor
+	Recipient Actor
+	Topic     string
+	Reason    error
+	Timestamp time.Time
+}
+
+// DeadLetterStore persists dead letters for post-mortem analysis
+type DeadLetterStore interface {
+	Store(letter DeadLetter) error
+}
+
+// DeadLetters collects undeliverable messages, counts them and forwards them
+// to its subscribers and store
+type DeadLetters struct {
+	subscribers []Actor
+	store       DeadLetterStore
+	eventStream *EventStream
+	count       atomic.Uint64
+	mu          sync.RWMutex
+}
+
+// NewDeadLetters creates a dead letter sink without subscribers or store
+func NewDeadLetters() *DeadLetters {
+	return &DeadLetters{}
+}
+
+// Subscribe makes actor receive a DeadLetter message for every undeliverable
+// message
+func (d *DeadLetters) Subscribe(actor Actor) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.subscribers = append(d.subscribers, actor)
+}
+
+// Unsubscribe stops actor from receiving dead letters
+func (d *DeadLetters) Unsubscribe(actor Actor) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for i, subscriber := range d.subscribers {
+		if subscriber.GetID() == actor.GetID() {
+			d.subscribers = append(d.subscribers[:i:i], d.subscribers[i+1:]...)
+			return
+		}
+	}
+}
+
+// SetStore persists every dead letter to store, nil disables persistence
+func (d *DeadLetters) SetStore(store DeadLetterStore) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.store = store
+}
+
+// SetEventStream also publishes every dead letter to eventStream, where
+// actors subscribe to DeadLetter. nil stops publishing.
+func (d *DeadLetters) SetEventStream(eventStream *EventStream) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.eventStream = eventStream
+}
+
+// Count returns the number of dead letters published so far
+func (d *DeadLetters) Count() uint64 {
+	return d.count.Load()
+}
+
+// Publish records an undeliverable message. Dead letters about dead letters,
+// e.g. for a stopped subscriber, are dropped to avoid loops.
+func (d *DeadLetters) Publish(letter DeadLetter) {
+	if _, ok := letter.Message.(DeadLetter); ok {
+		return
+	}
+	if letter.Timestamp.IsZero() {
+		letter.Timestamp = time.Now()
+	}
+	d.count.Add(1)
+
+	d.mu.RLock()
+	subscribers := d.subscribers
+	store := d.store
+	eventStream := d.eventStream
+	d.mu.RUnlock()
+
+	if store != nil {
+		if err := store.Store(letter); err != nil {
+			fmt.Printf("Could not store dead letter %v: %v\n", letter.Message, err)
+		}
+	}
+	for _, subscriber := range subscribers {
+		subscriber.SendMessage(letter)
+	}
+	if eventStream != nil {
+		eventStream.Publish(letter)
+	}
+}
+
+// deadLetter builds the dead letter for msg sent to recipient, unwrapping the
+// envelope added by Ask and Tell
+func deadLetter(msg interface{}, recipient Actor, reason error) DeadLetter {
+	letter := DeadLetter{
+		Message:   msg,
+		Recipient: recipient,
+		Reason:    reason,
+		Timestamp: time.Now(),
+	}
+	if env, ok := msg.(*envelope); ok {
+		letter.Message = env.message
+		letter.Sender = env.sender
+		if env.future != nil {
+			env.future.complete(nil, reason)
+		}
+	}
+	return letter
+}
+
+// DeadLetterLog is a DeadLetterStore writing one JSON object per line
+type DeadLetterLog struct {
+	w  io.Writer
+	mu sync.Mutex
+}
+
+// NewDeadLetterLog creates a store appending dead letters to w, e.g. a file
+func NewDeadLetterLog(w io.Writer) *DeadLetterLog {
+	return &DeadLetterLog{w: w}
+}
+
+type deadLetterRecord struct {
+	Timestamp   time.Time `json:"timestamp"`
+	Reason      string    `json:"reason"`
+	MessageType string    `json:"messageType"`
+	Message     string    `json:"message"`
+	Sender      uuid.UUID `json:"sender"`
+	Recipient   uuid.UUID `json:"recipient"`
+	Topic       string    `json:"topic,omitempty"`
+}
+
+func (l *DeadLetterLog) Store(letter DeadLetter) error {
+	record := deadLetterRecord{
+		Timestamp:   letter.Timestamp,
+		MessageType: fmt.Sprintf("%T", letter.Message),
+		Message:     fmt.Sprintf("%v", letter.Message),
+		Topic:       letter.Topic,
+	}
+	if letter.Reason != nil {
+		record.Reason = letter.Reason.Error()
+	}
+	if letter.Sender != nil {
+		record.Sender = letter.Sender.GetID()
+	}
+	if letter.Recipient != nil {
+		record.Recipient = letter.Recipient.GetID()
+	}
+
+	line, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	_, err = l.w.Write(append(line, '\n'))
+	return err
+}
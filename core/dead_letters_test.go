@@ -0,0 +1,205 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+// collectDeadLetters returns a sink whose subscriber forwards dead letters
+// to the returned channel
+func collectDeadLetters(t *testing.T) (*DeadLetters, chan DeadLetter) {
+	t.Helper()
+	letters := make(chan DeadLetter, 10)
+	listener := NewBasicActor("dead-letter-listener")
+	listener.ReceiveFunc = func(result *ActorResult) *ActorResult {
+		letters <- result.Message.(DeadLetter)
+		return &ActorResult{}
+	}
+	listener.Start()
+	t.Cleanup(listener.Stop)
+
+	deadLetters := NewDeadLetters()
+	deadLetters.Subscribe(listener)
+	return deadLetters, letters
+}
+
+func expectDeadLetter(t *testing.T, letters chan DeadLetter) DeadLetter {
+	t.Helper()
+	select {
+	case letter := <-letters:
+		return letter
+	case <-time.After(time.Second):
+		t.Fatalf("expected a dead letter")
+		return DeadLetter{}
+	}
+}
+
+// Test suite for DeadLetters
+func TestDeadLetters(t *testing.T) {
+
+	t.Run("TestFullMailbox", func(t *testing.T) {
+		// Arrange
+		deadLetters, letters := collectDeadLetters(t)
+		actor := NewBasicActorWithMailboxSize("full-actor", 1)
+		actor.SetDeadLetters(deadLetters)
+		sender := NewBasicActor("sender")
+
+		// Act
+		actor.SendMessage("first")
+		Tell(actor, "second", sender)
+
+		// Assert
+		letter := expectDeadLetter(t, letters)
+		if letter.Message != "second" || !errors.Is(letter.Reason, ErrMailboxFull) {
+			t.Errorf("expected 'second' to be dropped for a full mailbox, got %v (%v)", letter.Message, letter.Reason)
+		}
+		if letter.Sender != sender || letter.Recipient.GetID() != actor.GetID() || letter.Timestamp.IsZero() {
+			t.Errorf("expected sender, recipient and timestamp to be recorded, got %+v", letter)
+		}
+		if deadLetters.Count() != 1 {
+			t.Errorf("expected 1 dead letter, got %d", deadLetters.Count())
+		}
+	})
+
+	t.Run("TestStoppedActor", func(t *testing.T) {
+		// Arrange
+		deadLetters, letters := collectDeadLetters(t)
+		release := make(chan struct{})
+		actor := NewBasicActor("stopped-actor")
+		actor.SetDeadLetters(deadLetters)
+		actor.ReceiveFunc = func(result *ActorResult) *ActorResult {
+			<-release
+			return &ActorResult{}
+		}
+		actor.Start()
+		actor.SendMessage("processing")
+		actor.SendMessage("queued")
+		time.Sleep(20 * time.Millisecond)
+
+		// Act
+		actor.Stop()
+		close(release)
+		actor.awaitTermination()
+		_, err := Ask(context.Background(), actor, "too late")
+
+		// Assert
+		if !errors.Is(err, ErrActorStopped) {
+			t.Errorf("expected Ask to fail with ErrActorStopped, got %v", err)
+		}
+		for _, want := range []interface{}{"queued", "too late"} {
+			letter := expectDeadLetter(t, letters)
+			if letter.Message != want || !errors.Is(letter.Reason, ErrActorStopped) {
+				t.Errorf("expected %v to be a dead letter of a stopped actor, got %v (%v)", want, letter.Message, letter.Reason)
+			}
+		}
+	})
+
+	t.Run("TestTopicWithoutSubscribers", func(t *testing.T) {
+		// Arrange
+		deadLetters, letters := collectDeadLetters(t)
+		broker := NewInMemoryBroker()
+		broker.SetDeadLetters(deadLetters)
+
+		// Act
+		err := broker.Publish("nobody", "hello")
+
+		// Assert
+		if !errors.Is(err, ErrNoSubscribers) {
+			t.Errorf("expected ErrNoSubscribers, got %v", err)
+		}
+		letter := expectDeadLetter(t, letters)
+		if letter.Message != "hello" || letter.Topic != "nobody" || letter.Recipient != nil {
+			t.Errorf("expected the message and topic to be recorded, got %+v", letter)
+		}
+	})
+
+	t.Run("TestDeadLettersOfDeadLettersAreDropped", func(t *testing.T) {
+		// Arrange
+		deadLetters := NewDeadLetters()
+		listener := NewBasicActor("stopped-listener")
+		listener.SetDeadLetters(deadLetters)
+		listener.ReceiveFunc = func(result *ActorResult) *ActorResult {
+			return &ActorResult{}
+		}
+		listener.Start()
+		listener.Stop()
+		listener.awaitTermination()
+		deadLetters.Subscribe(listener)
+
+		// Act
+		deadLetters.Publish(DeadLetter{Message: "lost", Reason: ErrActorStopped})
+
+		// Assert
+		if deadLetters.Count() != 1 {
+			t.Errorf("expected only the original dead letter to be counted, got %d", deadLetters.Count())
+		}
+	})
+
+	t.Run("TestDeadLetterLog", func(t *testing.T) {
+		// Arrange
+		var buf bytes.Buffer
+		deadLetters := NewDeadLetters()
+		deadLetters.SetStore(NewDeadLetterLog(&buf))
+		recipient := NewBasicActor("recipient")
+
+		// Act
+		deadLetters.Publish(DeadLetter{Message: 42, Recipient: recipient, Reason: ErrMailboxFull})
+
+		// Assert
+		var record map[string]interface{}
+		if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+			t.Fatalf("expected a JSON line, got %q: %v", buf.String(), err)
+		}
+		if record["message"] != "42" || record["messageType"] != "int" || record["reason"] != "mailbox full" ||
+			record["recipient"] != recipient.GetID().String() {
+			t.Errorf("unexpected record %v", record)
+		}
+	})
+
+	t.Run("TestActorSystemDeadLetters", func(t *testing.T) {
+		// Arrange
+		system := NewActorSystem(context.Background(), "test-system")
+		defer system.Shutdown()
+		actor, _ := system.ActorOf(PropsFromFunc(func(result *ActorResult) *ActorResult {
+			return &ActorResult{}
+		}), "short-lived")
+		system.Stop(actor)
+		asBasicActor(actor).awaitTermination()
+
+		// Act
+		actor.SendMessage("after stop")
+		system.Broker().Publish("nobody", "unheard")
+
+		// Assert
+		if system.DeadLetters().Count() != 2 {
+			t.Errorf("expected 2 dead letters, got %d", system.DeadLetters().Count())
+		}
+	})
+
+	t.Run("TestActorSystemPublishesDeadLetterEvents", func(t *testing.T) {
+		// Arrange
+		system := NewActorSystem(context.Background(), "test-system")
+		defer system.Shutdown()
+		letters := make(chan DeadLetter, 10)
+		listener := NewBasicActor("event-listener")
+		listener.ReceiveFunc = func(result *ActorResult) *ActorResult {
+			letters <- result.Message.(DeadLetter)
+			return &ActorResult{}
+		}
+		listener.Start()
+		defer listener.Stop()
+		system.EventStream().Subscribe(listener, DeadLetter{})
+
+		// Act
+		system.Broker().Publish("nobody", "unheard")
+
+		// Assert
+		if letter := expectDeadLetter(t, letters); letter.Message != "unheard" || letter.Topic != "nobody" {
+			t.Errorf("expected the unheard message on the event stream, got %+v", letter)
+		}
+	})
+}
@@ -0,0 +1,113 @@
+package core
+
+import (
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// Terminated is delivered to watchers when a watched actor stops for good.
+// Reason is nil for a plain Stop, the context error when the actor's context
+// was cancelled, or the failure it was stopped for.
+type Terminated struct {
+	ID     uuid.UUID
+	Name   string
+	Reason error
+}
+
+// SystemMessage delivers Terminated ahead of queued user messages
+func (Terminated) SystemMessage() {}
+
+// Watch makes watcher receive a Terminated message when watched stops. Restarts
+// by a supervisor do not count as stopping, and the watch follows the actor to
+// its next incarnation, also when watched is a reference taken before a
+// restart. Watching an actor that already stopped delivers Terminated
+// immediately.
+func Watch(watcher, watched Actor) error {
+	target := asBasicActor(watched)
+	if target == nil {
+		return fmt.Errorf("actor %s does not support being watched", watched.GetID())
+	}
+	watchedID := target.sharedIdentity()
+
+	watchedID.mu.Lock()
+	terminated := watchedID.terminated
+	if !terminated {
+		if watchedID.watchers == nil {
+			watchedID.watchers = make(map[uuid.UUID]Actor)
+		}
+		watchedID.watchers[watcher.GetID()] = watcher
+	}
+	watchedID.mu.Unlock()
+
+	if terminated {
+		watcher.SendMessage(Terminated{ID: target.id, Name: target.name})
+		return nil
+	}
+
+	if w := asBasicActor(watcher); w != nil {
+		watcherID := w.sharedIdentity()
+		watcherID.mu.Lock()
+		if watcherID.watching == nil {
+			watcherID.watching = make(map[uuid.UUID]*identity)
+		}
+		watcherID.watching[target.id] = watchedID
+		watcherID.mu.Unlock()
+	}
+	return nil
+}
+
+// Unwatch stops watcher from being notified when watched stops
+func Unwatch(watcher, watched Actor) {
+	if target := asBasicActor(watched); target != nil {
+		target.sharedIdentity().removeWatcher(watcher.GetID())
+	}
+	if w := asBasicActor(watcher); w != nil {
+		watcherID := w.sharedIdentity()
+		watcherID.mu.Lock()
+		delete(watcherID.watching, watched.GetID())
+		watcherID.mu.Unlock()
+	}
+}
+
+func (id *identity) removeWatcher(watcher uuid.UUID) {
+	id.mu.Lock()
+	defer id.mu.Unlock()
+	delete(id.watchers, watcher)
+}
+
+// terminate notifies the watchers that the actor stopped for good, drops the
+// watches it held on other actors and removes it from its parent and registry
+func (a *BasicActor) terminate() {
+	a.mu.Lock()
+	reason := a.failure
+	if reason == nil && a.ctx.Err() != nil {
+		reason = a.ctx.Err()
+	}
+	a.mu.Unlock()
+	id := a.sharedIdentity()
+	id.mu.Lock()
+	id.terminated = true
+	watchers := id.watchers
+	watching := id.watching
+	id.watchers = nil
+	id.watching = nil
+	id.mu.Unlock()
+
+	if mailbox, ok := a.mailbox.(interface{ Close() }); ok {
+		mailbox.Close()
+	}
+	a.drainToDeadLetters()
+	for _, watcher := range watchers {
+		watcher.SendMessage(Terminated{ID: a.id, Name: a.name, Reason: reason})
+	}
+	for _, watched := range watching {
+		watched.removeWatcher(a.id)
+	}
+	if parent := a.getParent(); parent != nil {
+		parent.removeChild(a)
+	}
+	if a.system != nil && a.path != "" {
+		a.system.registry.unregisterActorIf(a.path, a.id)
+	}
+}
@@ -0,0 +1,210 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func newWatcher(t *testing.T) (*BasicActor, chan Terminated) {
+	t.Helper()
+	terminated := make(chan Terminated, 10)
+	watcher := NewBasicActor("watcher")
+	watcher.ReceiveFunc = func(result *ActorResult) *ActorResult {
+		if msg, ok := result.Message.(Terminated); ok {
+			terminated <- msg
+		}
+		return &ActorResult{}
+	}
+	watcher.Start()
+	t.Cleanup(watcher.Stop)
+	return watcher, terminated
+}
+
+func expectTerminated(t *testing.T, terminated chan Terminated) Terminated {
+	t.Helper()
+	select {
+	case msg := <-terminated:
+		return msg
+	case <-time.After(time.Second):
+		t.Fatalf("expected a Terminated message")
+	}
+	return Terminated{}
+}
+
+// Test suite for death watch
+func TestDeathWatch(t *testing.T) {
+
+	t.Run("TestWatchStoppedActor", func(t *testing.T) {
+		// Arrange
+		watcher, terminated := newWatcher(t)
+		watched := NewBasicActor("watched")
+		watched.ReceiveFunc = func(result *ActorResult) *ActorResult {
+			return &ActorResult{}
+		}
+		watched.Start()
+		Watch(watcher, watched)
+
+		// Act
+		watched.Stop()
+
+		// Assert
+		msg := expectTerminated(t, terminated)
+		if msg.ID != watched.GetID() || msg.Name != "watched" || msg.Reason != nil {
+			t.Errorf("unexpected Terminated message %+v", msg)
+		}
+	})
+
+	t.Run("TestWatchContextCancellation", func(t *testing.T) {
+		// Arrange
+		watcher, terminated := newWatcher(t)
+		ctx, cancel := context.WithCancel(context.Background())
+		watched := NewBasicActor("watched")
+		watched.SetContext(ctx)
+		watched.Start()
+		Watch(watcher, watched)
+
+		// Act
+		cancel()
+
+		// Assert
+		msg := expectTerminated(t, terminated)
+		if !errors.Is(msg.Reason, context.Canceled) {
+			t.Errorf("expected context cancellation as the reason, got %v", msg.Reason)
+		}
+	})
+
+	t.Run("TestWatchSurvivesRestart", func(t *testing.T) {
+		// Arrange
+		watcher, terminated := newWatcher(t)
+		system := NewActorSystem(context.Background(), "test-system")
+		defer system.Shutdown()
+		system.ActorOf(PropsFromFunc(func(result *ActorResult) *ActorResult {
+			return &ActorResult{Error: errors.New("boom")}
+		}), "worker")
+		watched, _ := system.Lookup("/user/worker")
+		Watch(watcher, watched)
+
+		// Act
+		watched.SendMessage("fail")
+		time.Sleep(100 * time.Millisecond)
+		current, _ := system.Lookup("/user/worker")
+		system.Stop(current)
+
+		// Assert
+		msg := expectTerminated(t, terminated)
+		if msg.ID != watched.GetID() {
+			t.Errorf("expected Terminated for the watched actor, got %+v", msg)
+		}
+		if len(terminated) != 0 {
+			t.Errorf("expected no Terminated message for the restart")
+		}
+	})
+
+	t.Run("TestWatchReportsFailureReason", func(t *testing.T) {
+		// Arrange
+		watcher, terminated := newWatcher(t)
+		supervisor := NewSupervisor(context.Background())
+		supervisor.SetRestartIntensity(1, time.Second)
+		watched := supervisor.SuperviseProps(PropsFromFunc(func(result *ActorResult) *ActorResult {
+			return &ActorResult{Error: errors.New("boom")}
+		}), "flaky")
+		Watch(watcher, watched)
+
+		// Act
+		watched.SendMessage("fail")
+		watched.SendMessage("fail")
+
+		// Assert
+		msg := expectTerminated(t, terminated)
+		if msg.Reason == nil {
+			t.Errorf("expected a failure reason, got nil")
+		}
+	})
+
+	t.Run("TestUnwatch", func(t *testing.T) {
+		// Arrange
+		watcher, terminated := newWatcher(t)
+		watched := NewBasicActor("watched")
+		watched.Start()
+		Watch(watcher, watched)
+
+		// Act
+		Unwatch(watcher, watched)
+		watched.Stop()
+		time.Sleep(50 * time.Millisecond)
+
+		// Assert
+		if len(terminated) != 0 {
+			t.Errorf("expected no Terminated message after Unwatch")
+		}
+	})
+
+	t.Run("TestUnwatchAfterRestart", func(t *testing.T) {
+		// Arrange
+		watcher, terminated := newWatcher(t)
+		supervisor := NewSupervisor(context.Background())
+		defer supervisor.Stop()
+		watched := supervisor.SuperviseProps(PropsFromFunc(func(result *ActorResult) *ActorResult {
+			return &ActorResult{Error: errors.New("boom")}
+		}), "flaky")
+		Watch(watcher, watched)
+		watched.SendMessage("fail")
+		time.Sleep(50 * time.Millisecond) // Allow the restart to complete
+
+		// Act
+		Unwatch(watcher, watched)
+		watched.Stop()
+		time.Sleep(50 * time.Millisecond)
+
+		// Assert
+		if len(terminated) != 0 {
+			t.Errorf("expected no Terminated message after Unwatch")
+		}
+	})
+
+	t.Run("TestWatcherRestartedFromProps", func(t *testing.T) {
+		// Arrange
+		terminated := make(chan Terminated, 10)
+		supervisor := NewSupervisor(context.Background())
+		defer supervisor.Stop()
+		watcher := supervisor.SuperviseProps(PropsFromFunc(func(result *ActorResult) *ActorResult {
+			if msg, ok := result.Message.(Terminated); ok {
+				terminated <- msg
+			}
+			if result.Message == "fail" {
+				return &ActorResult{Error: errors.New("boom")}
+			}
+			return &ActorResult{}
+		}), "watcher")
+		watched := NewBasicActor("watched")
+		watched.Start()
+		Watch(watcher, watched)
+
+		// Act
+		watcher.SendMessage("fail")
+		time.Sleep(50 * time.Millisecond) // Allow the restart to complete
+		watched.Stop()
+
+		// Assert
+		if msg := expectTerminated(t, terminated); msg.ID != watched.GetID() {
+			t.Errorf("expected the restarted watcher to be notified, got %+v", msg)
+		}
+	})
+
+	t.Run("TestWatchAlreadyStoppedActor", func(t *testing.T) {
+		// Arrange
+		watcher, terminated := newWatcher(t)
+		watched := NewBasicActor("watched")
+		watched.Start()
+		watched.Stop()
+		watched.awaitTermination()
+
+		// Act
+		Watch(watcher, watched)
+
+		// Assert
+		expectTerminated(t, terminated)
+	})
+}
@@ -0,0 +1,56 @@
+package core
+
+import (
+	"reflect"
+	"sync"
+)
+
+// EventStream delivers system events to the actors subscribed to their type
+type EventStream struct {
+	subscribers map[reflect.Type][]Actor
+	mu          sync.RWMutex
+}
+
+// NewEventStream creates an empty event stream
+func NewEventStream() *EventStream {
+	return &EventStream{
+		subscribers: make(map[reflect.Type][]Actor),
+	}
+}
+
+// Subscribe registers actor for events with the same type as event
+func (es *EventStream) Subscribe(actor Actor, event interface{}) {
+	es.mu.Lock()
+	defer es.mu.Unlock()
+
+	eventType := reflect.TypeOf(event)
+	es.subscribers[eventType] = append(es.subscribers[eventType], actor)
+}
+
+// Unsubscribe removes actor from the subscribers of event's type
+func (es *EventStream) Unsubscribe(actor Actor, event interface{}) {
+	es.mu.Lock()
+	defer es.mu.Unlock()
+
+	eventType := reflect.TypeOf(event)
+	subscribers := es.subscribers[eventType]
+	for i, subscriber := range subscribers {
+		if subscriber.GetID() == actor.GetID() {
+			es.subscribers[eventType] = append(subscribers[:i:i], subscribers[i+1:]...)
+			return
+		}
+	}
+}
+
+// Publish sends event to every actor subscribed to its type and reports
+// whether anyone was listening
+func (es *EventStream) Publish(event interface{}) bool {
+	es.mu.RLock()
+	defer es.mu.RUnlock()
+
+	subscribers := es.subscribers[reflect.TypeOf(event)]
+	for _, subscriber := range subscribers {
+		subscriber.SendMessage(event)
+	}
+	return len(subscribers) > 0
+}
@@ -0,0 +1,286 @@
+package core
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// stateTimeoutTimer is the timer key of the state timeout
+const stateTimeoutTimer = "fsm-state-timeout"
+
+// StateTimeout is handled by an FSM that received no message for the timeout
+// of its current state, see SetStateTimeout and NextState.ForMax
+type StateTimeout struct{}
+
+// stateTimeout is the timer message behind StateTimeout. Timers that fired
+// before the state changed, or for a previous incarnation sharing the
+// mailbox, are recognised by their owner and generation.
+type stateTimeout struct {
+	owner      interface{}
+	generation uint64
+}
+
+// CurrentState is sent to a transition listener when it subscribes
+type CurrentState[S comparable] struct {
+	FSM   Actor
+	State S
+}
+
+// Transition is sent to transition listeners when the FSM changes state
+type Transition[S comparable] struct {
+	FSM  Actor
+	From S
+	To   S
+}
+
+// FSMEvent is a message handled in a state, along with the state data. The
+// embedded ActorResult gives access to the message, Reply and Context.
+type FSMEvent[D any] struct {
+	*ActorResult
+	Data D
+}
+
+// StateFunc handles the messages of a state and returns the next state,
+// built with GoTo, Stay, Halt or Fail. Returning nil leaves the message
+// unhandled, see WhenUnhandled.
+type StateFunc[S comparable, D any] func(event FSMEvent[D]) *NextState[S, D]
+
+// NextState is the state an FSM moves to after handling a message
+type NextState[S comparable, D any] struct {
+	state      S
+	data       D
+	hasData    bool
+	transition bool
+	timeout    *time.Duration
+	halt       bool
+	err        error
+	action     int
+}
+
+// Using replaces the state data
+func (n *NextState[S, D]) Using(data D) *NextState[S, D] {
+	n.data = data
+	n.hasData = true
+	return n
+}
+
+// ForMax overrides the timeout of the next state, 0 disabling it
+func (n *NextState[S, D]) ForMax(timeout time.Duration) *NextState[S, D] {
+	n.timeout = &timeout
+	return n
+}
+
+// FSM is an actor modelled as a finite state machine with states S and
+// state data D. Handlers are declared per state with When instead of
+// switching on the state in a ReceiveFunc:
+//
+//	fsm := NewFSM[OrderState, Order]("order", Pending, Order{})
+//	fsm.When(Pending, func(event FSMEvent[Order]) *NextState[OrderState, Order] {
+//		if payment, ok := event.Message.(Payment); ok {
+//			return fsm.GoTo(Paid).Using(event.Data.Pay(payment))
+//		}
+//		return nil
+//	})
+//
+// State timeouts use the actor's timers and are cancelled when it stops or
+// restarts. The machine starts over from its initial state and data every
+// time it is started, including restarts by its supervisor.
+type FSM[S comparable, D any] struct {
+	*BasicActor
+	initialState S
+	initialData  D
+	handlers     map[S]StateFunc[S, D]
+	timeouts     map[S]time.Duration
+	unhandled    StateFunc[S, D]
+	onTransition []func(from, to S)
+	// generation is incremented whenever the state timeout is rearmed. It
+	// needs no lock: it is only used by receive and PreStart, which both run
+	// on the actor's run loop.
+	generation uint64
+	mu         sync.Mutex
+	state      S
+	data       D
+	listeners  map[uuid.UUID]Actor
+}
+
+// NewFSM creates a state machine starting in initialState with initialData
+func NewFSM[S comparable, D any](name string, initialState S, initialData D) *FSM[S, D] {
+	f := &FSM[S, D]{
+		BasicActor:   NewBasicActor(name),
+		initialState: initialState,
+		initialData:  initialData,
+		handlers:     make(map[S]StateFunc[S, D]),
+		timeouts:     make(map[S]time.Duration),
+		state:        initialState,
+		data:         initialData,
+		listeners:    make(map[uuid.UUID]Actor),
+	}
+	f.BasicActor.self = f
+	f.BasicActor.ReceiveFunc = f.receive
+	return f
+}
+
+// When sets the handler of the messages received in state. Must be called
+// before the FSM is started.
+func (f *FSM[S, D]) When(state S, handler StateFunc[S, D]) {
+	f.handlers[state] = handler
+}
+
+// SetStateTimeout makes the FSM handle StateTimeout after timeout without
+// messages in state. 0 disables it. Must be called before the FSM is started.
+func (f *FSM[S, D]) SetStateTimeout(state S, timeout time.Duration) {
+	f.timeouts[state] = timeout
+}
+
+// WhenUnhandled sets the handler of the messages the current state's handler
+// returned nil for, e.g. queries answered in every state. Messages it does
+// not handle either are logged and dropped.
+func (f *FSM[S, D]) WhenUnhandled(handler StateFunc[S, D]) {
+	f.unhandled = handler
+}
+
+// OnTransition adds a hook called on every transition, after the new state
+// and data are set. Must be called before the FSM is started.
+func (f *FSM[S, D]) OnTransition(hook func(from, to S)) {
+	f.onTransition = append(f.onTransition, hook)
+}
+
+// GoTo moves to state, with a transition even if it is the current state
+func (f *FSM[S, D]) GoTo(state S) *NextState[S, D] {
+	return &NextState[S, D]{state: state, transition: true}
+}
+
+// Stay keeps the current state without a transition
+func (f *FSM[S, D]) Stay() *NextState[S, D] {
+	return &NextState[S, D]{state: f.State()}
+}
+
+// Halt stops the actor once the message is handled
+func (f *FSM[S, D]) Halt() *NextState[S, D] {
+	return &NextState[S, D]{state: f.State(), halt: true}
+}
+
+// Fail keeps the current state and reports err to the supervisor with
+// action, e.g. ACTOR_RESTART
+func (f *FSM[S, D]) Fail(err error, action int) *NextState[S, D] {
+	return &NextState[S, D]{state: f.State(), err: err, action: action}
+}
+
+// State returns the current state
+func (f *FSM[S, D]) State() S {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.state
+}
+
+// Data returns the current state data
+func (f *FSM[S, D]) Data() D {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.data
+}
+
+// SubscribeTransitions sends listener the current state as CurrentState,
+// then a Transition for every state change until UnsubscribeTransitions
+func (f *FSM[S, D]) SubscribeTransitions(listener Actor) {
+	f.mu.Lock()
+	f.listeners[listener.GetID()] = listener
+	state := f.state
+	f.mu.Unlock()
+	listener.SendMessage(CurrentState[S]{FSM: f.outer(), State: state})
+}
+
+// UnsubscribeTransitions stops sending transitions to listener
+func (f *FSM[S, D]) UnsubscribeTransitions(listener Actor) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.listeners, listener.GetID())
+}
+
+// PreStart resets the machine to its initial state
+func (f *FSM[S, D]) PreStart() error {
+	f.mu.Lock()
+	f.state = f.initialState
+	f.data = f.initialData
+	f.mu.Unlock()
+	f.armStateTimeout(f.timeouts[f.initialState])
+	return nil
+}
+
+func (f *FSM[S, D]) receive(result *ActorResult) *ActorResult {
+	if timeout, ok := result.Message.(stateTimeout); ok {
+		if timeout.owner != f || timeout.generation != f.generation {
+			return &ActorResult{}
+		}
+		result.Message = StateTimeout{}
+	}
+
+	state, data := f.State(), f.Data()
+	event := FSMEvent[D]{ActorResult: result, Data: data}
+	var next *NextState[S, D]
+	if handler, ok := f.handlers[state]; ok {
+		next = handler(event)
+	}
+	if next == nil && f.unhandled != nil {
+		next = f.unhandled(event)
+	}
+	if next == nil {
+		fmt.Printf("FSM %s dropping unhandled message %T in state %v\n", f.GetID(), result.Message, state)
+		next = f.Stay()
+	}
+	return f.apply(result, state, next)
+}
+
+// apply makes next the current state, running the transition hooks and
+// rearming the state timeout
+func (f *FSM[S, D]) apply(result *ActorResult, from S, next *NextState[S, D]) *ActorResult {
+	f.mu.Lock()
+	f.state = next.state
+	if next.hasData {
+		f.data = next.data
+	}
+	listeners := make([]Actor, 0, len(f.listeners))
+	for _, listener := range f.listeners {
+		listeners = append(listeners, listener)
+	}
+	f.mu.Unlock()
+
+	if next.transition {
+		for _, hook := range f.onTransition {
+			hook(from, next.state)
+		}
+		transition := Transition[S]{FSM: f.outer(), From: from, To: next.state}
+		for _, listener := range listeners {
+			listener.SendMessage(transition)
+		}
+	}
+
+	if next.halt {
+		f.CancelTimer(stateTimeoutTimer)
+		result.Context().Stop(f.outer())
+		return &ActorResult{}
+	}
+	timeout := f.timeouts[next.state]
+	if next.timeout != nil {
+		timeout = *next.timeout
+	}
+	f.armStateTimeout(timeout)
+	if next.err != nil {
+		return &ActorResult{Error: next.err, Action: next.action}
+	}
+	return &ActorResult{}
+}
+
+// armStateTimeout restarts the state timeout, invalidating timeouts already
+// delivered. Only called from the run loop, see generation.
+func (f *FSM[S, D]) armStateTimeout(timeout time.Duration) {
+	f.generation++
+	if timeout <= 0 {
+		f.CancelTimer(stateTimeoutTimer)
+		return
+	}
+	f.StartSingleTimer(stateTimeoutTimer, stateTimeout{owner: f, generation: f.generation}, timeout)
+}
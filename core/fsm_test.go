@@ -0,0 +1,220 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+type orderState int
+
+const (
+	ORDER_PENDING orderState = iota
+	ORDER_PAID
+	ORDER_SHIPPED
+	ORDER_CANCELLED
+)
+
+// order is the state data of the order FSM
+type order struct {
+	items int
+	paid  int
+}
+
+// newOrderFSM creates an order lifecycle: items are added while pending, a
+// payment moves it to paid and shipping ends it. "state" is answered in
+// every state.
+func newOrderFSM() *FSM[orderState, order] {
+	fsm := NewFSM[orderState, order]("order", ORDER_PENDING, order{})
+	fsm.When(ORDER_PENDING, func(event FSMEvent[order]) *NextState[orderState, order] {
+		switch msg := event.Message.(type) {
+		case string:
+			if msg == "add" {
+				return fsm.Stay().Using(order{items: event.Data.items + 1})
+			}
+		case int:
+			return fsm.GoTo(ORDER_PAID).Using(order{items: event.Data.items, paid: msg})
+		case StateTimeout:
+			return fsm.GoTo(ORDER_CANCELLED)
+		}
+		return nil
+	})
+	fsm.When(ORDER_PAID, func(event FSMEvent[order]) *NextState[orderState, order] {
+		switch event.Message {
+		case "ship":
+			return fsm.GoTo(ORDER_SHIPPED)
+		case "fail":
+			return fsm.Fail(errors.New("payment provider down"), ACTOR_RESTART)
+		}
+		return nil
+	})
+	fsm.When(ORDER_SHIPPED, func(event FSMEvent[order]) *NextState[orderState, order] {
+		if event.Message == "archive" {
+			return fsm.Halt()
+		}
+		return nil
+	})
+	fsm.WhenUnhandled(func(event FSMEvent[order]) *NextState[orderState, order] {
+		if event.Message == "state" {
+			event.Reply(fsm.State())
+			return fsm.Stay()
+		}
+		return nil
+	})
+	return fsm
+}
+
+func expectState(t *testing.T, fsm *FSM[orderState, order], expected orderState) {
+	t.Helper()
+	if state := askWithTimeout(t, fsm, "state"); state != expected {
+		t.Errorf("expected state %v, got %v", expected, state)
+	}
+}
+
+// Test suite for FSM actors
+func TestFSM(t *testing.T) {
+
+	t.Run("TestTransitions", func(t *testing.T) {
+		// Arrange
+		fsm := newOrderFSM()
+		var transitions [][2]orderState
+		fsm.OnTransition(func(from, to orderState) {
+			transitions = append(transitions, [2]orderState{from, to})
+		})
+		fsm.Start()
+		defer fsm.Stop()
+
+		// Act
+		fsm.SendMessage("add")
+		fsm.SendMessage("add")
+		fsm.SendMessage(30)
+		fsm.SendMessage("ship")
+
+		// Assert
+		expectState(t, fsm, ORDER_SHIPPED)
+		if data := fsm.Data(); data.items != 2 || data.paid != 30 {
+			t.Errorf("expected 2 items paid 30, got %+v", data)
+		}
+		expected := [][2]orderState{{ORDER_PENDING, ORDER_PAID}, {ORDER_PAID, ORDER_SHIPPED}}
+		if len(transitions) != 2 || transitions[0] != expected[0] || transitions[1] != expected[1] {
+			t.Errorf("expected transitions %v, got %v", expected, transitions)
+		}
+	})
+
+	t.Run("TestUnhandledMessage", func(t *testing.T) {
+		// Arrange
+		fsm := newOrderFSM()
+		fsm.Start()
+		defer fsm.Stop()
+
+		// Act
+		fsm.SendMessage("ship")
+
+		// Assert
+		expectState(t, fsm, ORDER_PENDING)
+	})
+
+	t.Run("TestTransitionListener", func(t *testing.T) {
+		// Arrange
+		fsm := newOrderFSM()
+		listener, received := newRecordingActor("listener")
+		listener.Start()
+		defer listener.Stop()
+		fsm.Start()
+		defer fsm.Stop()
+
+		// Act
+		fsm.SubscribeTransitions(listener)
+		fsm.SendMessage(10)
+		expectState(t, fsm, ORDER_PAID)
+		fsm.UnsubscribeTransitions(listener)
+		fsm.SendMessage("ship")
+		expectState(t, fsm, ORDER_SHIPPED)
+
+		// Assert
+		if msg := <-received; msg != (CurrentState[orderState]{FSM: fsm, State: ORDER_PENDING}) {
+			t.Errorf("expected the current state first, got %v", msg)
+		}
+		if msg := <-received; msg != (Transition[orderState]{FSM: fsm, From: ORDER_PENDING, To: ORDER_PAID}) {
+			t.Errorf("expected the transition to paid, got %v", msg)
+		}
+		expectNoMessage(t, received, 50*time.Millisecond)
+	})
+
+	t.Run("TestStateTimeout", func(t *testing.T) {
+		// Arrange
+		fsm := newOrderFSM()
+		fsm.SetStateTimeout(ORDER_PENDING, 60*time.Millisecond)
+		fsm.Start()
+		defer fsm.Stop()
+
+		// Act
+		for i := 0; i < 3; i++ {
+			time.Sleep(30 * time.Millisecond)
+			fsm.SendMessage("add")
+		}
+		expectState(t, fsm, ORDER_PENDING)
+		time.Sleep(120 * time.Millisecond)
+
+		// Assert
+		expectState(t, fsm, ORDER_CANCELLED)
+	})
+
+	t.Run("TestStateTimeoutCancelledByTransition", func(t *testing.T) {
+		// Arrange
+		fsm := newOrderFSM()
+		fsm.SetStateTimeout(ORDER_PENDING, 40*time.Millisecond)
+		fsm.Start()
+		defer fsm.Stop()
+
+		// Act
+		fsm.SendMessage(10)
+		time.Sleep(80 * time.Millisecond)
+
+		// Assert
+		expectState(t, fsm, ORDER_PAID)
+	})
+
+	t.Run("TestHalt", func(t *testing.T) {
+		// Arrange
+		watcher, terminated := newWatcher(t)
+		fsm := newOrderFSM()
+		fsm.Start()
+		Watch(watcher, fsm)
+
+		// Act
+		fsm.SendMessage(10)
+		fsm.SendMessage("ship")
+		fsm.SendMessage("archive")
+
+		// Assert
+		if msg := expectTerminated(t, terminated); msg.ID != fsm.GetID() {
+			t.Errorf("expected the FSM to terminate, got %v", msg.Name)
+		}
+	})
+
+	t.Run("TestRestartResetsState", func(t *testing.T) {
+		// Arrange
+		supervisor := NewSupervisor(context.Background())
+		defer supervisor.Stop()
+		fsm := newOrderFSM()
+		supervisor.SuperviseActor(fsm)
+		fsm.SendMessage("add")
+		fsm.SendMessage(10)
+		expectState(t, fsm, ORDER_PAID)
+
+		// Act
+		fsm.SendMessage("fail")
+
+		// Assert
+		deadline := time.Now().Add(time.Second)
+		for fsm.State() != ORDER_PENDING && time.Now().Before(deadline) {
+			time.Sleep(10 * time.Millisecond)
+		}
+		expectState(t, fsm, ORDER_PENDING)
+		if data := fsm.Data(); data != (order{}) {
+			t.Errorf("expected the initial data after the restart, got %+v", data)
+		}
+	})
+}
@@ -0,0 +1,173 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrNoReplyTarget is returned by ActorResult.Reply when the message was
+	// neither sent with Ask nor carries a sender to answer to
+	ErrNoReplyTarget = errors.New("message has no reply target")
+	// ErrMailboxFull is returned when a message cannot be enqueued
+	ErrMailboxFull = errors.New("mailbox full")
+)
+
+// Failure is piped into an actor's mailbox when the future it is waiting on
+// completes with an error
+type Failure struct {
+	Err error
+}
+
+// Future is the pending result of an Ask
+type Future interface {
+	// Await blocks until the future completes or ctx is done
+	Await(ctx context.Context) (interface{}, error)
+	// Then returns a future completed with the result of fn once this future
+	// succeeds. Errors skip fn and are propagated as-is.
+	Then(fn func(value interface{}) (interface{}, error)) Future
+	// PipeTo delivers the result to the actor's mailbox, or a Failure on error
+	PipeTo(actor Actor)
+	// Done is closed when the future completes
+	Done() <-chan struct{}
+}
+
+type future struct {
+	once  sync.Once
+	done  chan struct{}
+	value interface{}
+	err   error
+}
+
+func newFuture() *future {
+	return &future{
+		done: make(chan struct{}),
+	}
+}
+
+// complete resolves the future, it returns false if it was already resolved
+func (f *future) complete(value interface{}, err error) bool {
+	completed := false
+	f.once.Do(func() {
+		f.value = value
+		f.err = err
+		close(f.done)
+		completed = true
+	})
+	return completed
+}
+
+func (f *future) Done() <-chan struct{} {
+	return f.done
+}
+
+func (f *future) Await(ctx context.Context) (interface{}, error) {
+	select {
+	case <-f.done:
+		return f.value, f.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (f *future) Then(fn func(value interface{}) (interface{}, error)) Future {
+	next := newFuture()
+	go func() {
+		<-f.done
+		if f.err != nil {
+			next.complete(nil, f.err)
+			return
+		}
+		next.complete(fn(f.value))
+	}()
+	return next
+}
+
+func (f *future) PipeTo(actor Actor) {
+	go func() {
+		<-f.done
+		if f.err != nil {
+			actor.SendMessage(&Failure{Err: f.err})
+			return
+		}
+		actor.SendMessage(f.value)
+	}()
+}
+
+// envelope wraps a message with the metadata needed to answer it
+type envelope struct {
+	message interface{}
+	sender  Actor
+	future  *future
+}
+
+// DefaultAskTimeout bounds an Ask whose context has no deadline, so asking
+// an actor that never replies does not wait forever
+const DefaultAskTimeout = 30 * time.Second
+
+// Ask sends msg to actor and returns a Future completed by the receiver
+// calling Reply on its ActorResult. The future fails with ctx.Err() once ctx
+// is done, so timeouts are expressed with context.WithTimeout. Without a
+// deadline on ctx, the future fails after DefaultAskTimeout.
+func Ask(ctx context.Context, actor Actor, msg interface{}) (Future, error) {
+	if actor == nil {
+		return nil, fmt.Errorf("cannot ask a nil actor")
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	f := newFuture()
+	if err := deliver(actor, &envelope{message: msg, future: f}); err != nil {
+		return nil, err
+	}
+
+	cancel := context.CancelFunc(func() {})
+	if _, ok := ctx.Deadline(); !ok {
+		ctx, cancel = context.WithTimeout(ctx, DefaultAskTimeout)
+	}
+	go func() {
+		defer cancel()
+		select {
+		case <-ctx.Done():
+			f.complete(nil, ctx.Err())
+		case <-f.done:
+		}
+	}()
+
+	return f, nil
+}
+
+// Tell sends msg to actor recording sender so the receiver can Reply to it
+func Tell(actor Actor, msg interface{}, sender Actor) {
+	actor.SendMessage(&envelope{message: msg, sender: sender})
+}
+
+// deliver enqueues msg reporting whether the mailbox accepted it when the
+// actor supports it
+func deliver(actor Actor, msg interface{}) error {
+	if t, ok := actor.(interface{ tell(msg interface{}) error }); ok {
+		return t.tell(msg)
+	}
+	actor.SendMessage(msg)
+	return nil
+}
+
+// Reply answers the message this result was created for. Asked messages
+// complete their Future, messages sent with Tell are answered to the sender.
+func (r *ActorResult) Reply(msg interface{}) error {
+	if r.future != nil {
+		if !r.future.complete(msg, nil) {
+			return fmt.Errorf("message already replied to")
+		}
+		return nil
+	}
+	if r.Sender != nil {
+		r.Sender.SendMessage(msg)
+		return nil
+	}
+	return ErrNoReplyTarget
+}
@@ -0,0 +1,153 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// Test suite for Ask and Future
+func TestFuture(t *testing.T) {
+
+	t.Run("TestAskReply", func(t *testing.T) {
+		// Arrange
+		actor := NewBasicActor("ask-actor")
+		actor.ReceiveFunc = func(result *ActorResult) *ActorResult {
+			result.Reply(result.Message.(int) * 2)
+			return &ActorResult{}
+		}
+		actor.Start()
+		defer actor.Stop()
+
+		// Act
+		future, err := Ask(context.Background(), actor, 21)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		value, err := future.Await(context.Background())
+
+		// Assert
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if value != 42 {
+			t.Errorf("expected 42, got %v", value)
+		}
+	})
+
+	t.Run("TestAskTimeout", func(t *testing.T) {
+		// Arrange
+		actor := NewBasicActor("silent-actor")
+		actor.ReceiveFunc = func(result *ActorResult) *ActorResult {
+			return &ActorResult{}
+		}
+		actor.Start()
+		defer actor.Stop()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		// Act
+		future, err := Ask(ctx, actor, "hello")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		_, err = future.Await(context.Background())
+
+		// Assert
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("expected deadline exceeded, got %v", err)
+		}
+	})
+
+	t.Run("TestAskFailsWithReceiveError", func(t *testing.T) {
+		// Arrange
+		failureChannel := make(chan *ActorResult, 1)
+		actor := NewBasicActor("failing-actor")
+		actor.ReceiveFunc = func(result *ActorResult) *ActorResult {
+			return &ActorResult{Error: errors.New("boom")}
+		}
+		actor.SetFailureChannel(failureChannel)
+		actor.Start()
+		defer actor.Stop()
+
+		// Act
+		future, _ := Ask(context.Background(), actor, "hello")
+		_, err := future.Await(context.Background())
+
+		// Assert
+		if err == nil || err.Error() != "boom" {
+			t.Errorf("expected receive error, got %v", err)
+		}
+	})
+
+	t.Run("TestThenAndPipeTo", func(t *testing.T) {
+		// Arrange
+		actor := NewBasicActor("ask-actor")
+		actor.ReceiveFunc = func(result *ActorResult) *ActorResult {
+			result.Reply("pong")
+			return &ActorResult{}
+		}
+		actor.Start()
+		defer actor.Stop()
+
+		piped := make(chan interface{}, 1)
+		target := NewBasicActor("pipe-target")
+		target.ReceiveFunc = func(result *ActorResult) *ActorResult {
+			piped <- result.Message
+			return &ActorResult{}
+		}
+		target.Start()
+		defer target.Stop()
+
+		// Act
+		future, _ := Ask(context.Background(), actor, "ping")
+		future.Then(func(value interface{}) (interface{}, error) {
+			return value.(string) + "!", nil
+		}).PipeTo(target)
+
+		// Assert
+		select {
+		case msg := <-piped:
+			if msg != "pong!" {
+				t.Errorf("expected 'pong!', got %v", msg)
+			}
+		case <-time.After(time.Second):
+			t.Errorf("expected piped result to be delivered")
+		}
+	})
+
+	t.Run("TestReplyToSender", func(t *testing.T) {
+		// Arrange
+		replies := make(chan interface{}, 1)
+		sender := NewBasicActor("sender")
+		sender.ReceiveFunc = func(result *ActorResult) *ActorResult {
+			replies <- result.Message
+			return &ActorResult{}
+		}
+		sender.Start()
+		defer sender.Stop()
+
+		receiver := NewBasicActor("receiver")
+		receiver.ReceiveFunc = func(result *ActorResult) *ActorResult {
+			result.Reply("ack")
+			return &ActorResult{}
+		}
+		receiver.Start()
+		defer receiver.Stop()
+
+		// Act
+		Tell(receiver, "hello", sender)
+
+		// Assert
+		select {
+		case msg := <-replies:
+			if msg != "ack" {
+				t.Errorf("expected 'ack', got %v", msg)
+			}
+		case <-time.After(time.Second):
+			t.Errorf("expected reply to be delivered to sender")
+		}
+	})
+}
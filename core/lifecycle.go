@@ -0,0 +1,84 @@
+package core
+
+// PreStarter is implemented by actors that acquire resources before handling
+// their first message. Returning an error reports a failure to the supervisor.
+type PreStarter interface {
+	PreStart() error
+}
+
+// PostStopper is implemented by actors that release resources once stopped
+type PostStopper interface {
+	PostStop()
+}
+
+// PreRestarter is implemented by actors that clean up before being restarted.
+// It is called on the failed incarnation instead of PostStop, with the
+// failure and the message being processed when it happened, which is nil for
+// siblings restarted along with the failed actor. Actors without it get
+// PostStop called instead.
+type PreRestarter interface {
+	PreRestart(reason error, message interface{})
+}
+
+// PostRestarter is implemented by actors that reinitialise after a restart.
+// It is called on the new incarnation instead of PreStart. Actors without it
+// get PreStart called instead.
+type PostRestarter interface {
+	PostRestart(reason error)
+}
+
+// restartCause is why an actor is being restarted
+type restartCause struct {
+	reason  error
+	message interface{}
+}
+
+// preStart runs the start hook of the incarnation: PostRestart after a
+// restart when implemented, PreStart otherwise
+func (a *BasicActor) preStart() error {
+	self := a.outer()
+	if cause := a.restartedFrom; cause != nil {
+		a.restartedFrom = nil
+		if hook, ok := self.(PostRestarter); ok {
+			hook.PostRestart(cause.reason)
+			return nil
+		}
+	}
+	if hook, ok := self.(PreStarter); ok {
+		return hook.PreStart()
+	}
+	return nil
+}
+
+// postStop runs the stop hook of the incarnation: PreRestart when the
+// supervisor is restarting it and it is implemented, PostStop otherwise. It
+// reports whether the actor is being restarted.
+func (a *BasicActor) postStop() bool {
+	self := a.outer()
+	a.mu.Lock()
+	cause := a.restarting
+	a.restarting = nil
+	a.mu.Unlock()
+
+	if cause != nil {
+		if hook, ok := self.(PreRestarter); ok {
+			hook.PreRestart(cause.reason, cause.message)
+			return true
+		}
+	}
+	if hook, ok := self.(PostStopper); ok {
+		hook.PostStop()
+	}
+	return cause != nil
+}
+
+// outer returns the actor embedding this BasicActor when known, so hooks
+// implemented by user types are found
+func (a *BasicActor) outer() Actor {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.self != nil {
+		return a.self
+	}
+	return a
+}
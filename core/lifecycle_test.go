@@ -0,0 +1,191 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+// connectionActor records its lifecycle hooks like an actor owning a connection
+type connectionActor struct {
+	*BasicActor
+	incarnation int
+	events      chan string
+	startErr    error
+}
+
+func newConnectionActor(incarnation int, events chan string) *connectionActor {
+	a := &connectionActor{
+		BasicActor:  NewBasicActor("connection"),
+		incarnation: incarnation,
+		events:      events,
+	}
+	a.ReceiveFunc = func(result *ActorResult) *ActorResult {
+		if result.Message == "fail" {
+			return &ActorResult{Error: errors.New("connection lost")}
+		}
+		return &ActorResult{}
+	}
+	return a
+}
+
+func (a *connectionActor) PreStart() error {
+	a.events <- fmt.Sprintf("PreStart %d", a.incarnation)
+	return a.startErr
+}
+
+func (a *connectionActor) PostStop() {
+	a.events <- fmt.Sprintf("PostStop %d", a.incarnation)
+}
+
+func (a *connectionActor) PreRestart(reason error, message interface{}) {
+	a.events <- fmt.Sprintf("PreRestart %d: %v (%v)", a.incarnation, reason, message)
+}
+
+func (a *connectionActor) PostRestart(reason error) {
+	a.events <- fmt.Sprintf("PostRestart %d: %v", a.incarnation, reason)
+}
+
+// reconnectingActor only opens and closes its connection, relying on the
+// restart hooks falling back to PreStart and PostStop
+type reconnectingActor struct {
+	*BasicActor
+	events   chan string
+	startErr error
+}
+
+func (a *reconnectingActor) PreStart() error {
+	a.events <- "connect"
+	return a.startErr
+}
+
+func (a *reconnectingActor) PostStop() {
+	a.events <- "disconnect"
+}
+
+func expectEvents(t *testing.T, events chan string, expected ...string) {
+	t.Helper()
+	for _, want := range expected {
+		select {
+		case got := <-events:
+			if got != want {
+				t.Errorf("expected %q, got %q", want, got)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("expected %q to be recorded", want)
+		}
+	}
+}
+
+// Test suite for lifecycle hooks
+func TestLifecycleHooks(t *testing.T) {
+
+	t.Run("TestStartAndStopHooks", func(t *testing.T) {
+		// Arrange
+		events := make(chan string, 10)
+		supervisor := NewSupervisor(context.Background())
+		actor := newConnectionActor(1, events)
+
+		// Act
+		supervisor.SuperviseActor(actor)
+		supervisor.StopActor(actor)
+
+		// Assert
+		expectEvents(t, events, "PreStart 1", "PostStop 1")
+		supervisor.Stop()
+	})
+
+	t.Run("TestRestartHooks", func(t *testing.T) {
+		// Arrange
+		events := make(chan string, 10)
+		incarnation := 0
+		supervisor := NewSupervisor(context.Background())
+		defer supervisor.Stop()
+		actor := supervisor.SuperviseProps(PropsFromProducer(func() Actor {
+			incarnation++
+			return newConnectionActor(incarnation, events)
+		}), "connection")
+		expectEvents(t, events, "PreStart 1")
+
+		// Act
+		actor.SendMessage("fail")
+
+		// Assert
+		expectEvents(t, events,
+			"PreRestart 1: connection lost (fail)",
+			"PostRestart 2: connection lost",
+		)
+	})
+
+	t.Run("TestSiblingRestartHooksHaveNoMessage", func(t *testing.T) {
+		// Arrange
+		events := make(chan string, 10)
+		supervisor := NewSupervisorWithStrategy(context.Background(), ONE_FOR_ALL)
+		defer supervisor.Stop()
+		failing := supervisor.SuperviseProps(PropsFromFunc(func(result *ActorResult) *ActorResult {
+			return &ActorResult{Error: errors.New("connection lost")}
+		}), "failing")
+		supervisor.SuperviseActor(newConnectionActor(1, events))
+		expectEvents(t, events, "PreStart 1")
+
+		// Act
+		failing.SendMessage("fail")
+
+		// Assert
+		expectEvents(t, events,
+			"PreRestart 1: connection lost (<nil>)",
+			"PostRestart 1: connection lost",
+		)
+	})
+
+	t.Run("TestRestartHooksFallBackToStartAndStop", func(t *testing.T) {
+		// Arrange
+		events := make(chan string, 10)
+		supervisor := NewSupervisor(context.Background())
+		defer supervisor.Stop()
+		actor := &reconnectingActor{BasicActor: NewBasicActor("reconnecting"), events: events}
+		actor.ReceiveFunc = func(result *ActorResult) *ActorResult {
+			return &ActorResult{Error: errors.New("connection lost")}
+		}
+		supervisor.SuperviseActor(actor)
+		expectEvents(t, events, "connect")
+
+		// Act
+		actor.SendMessage("fail")
+
+		// Assert
+		expectEvents(t, events, "disconnect", "connect")
+	})
+
+	t.Run("TestPreStartErrorIsReported", func(t *testing.T) {
+		// Arrange
+		events := make(chan string, 10)
+		terminal := make(chan error, 1)
+		supervisor := NewSupervisor(context.Background())
+		defer supervisor.Stop()
+		supervisor.SetRestartIntensity(1, time.Second)
+		supervisor.SetTerminalFailureHandler(func(result *SupervisorActorResult) {
+			terminal <- result.Result.Error
+		})
+		actor := &reconnectingActor{
+			BasicActor: NewBasicActor("reconnecting"),
+			events:     events,
+			startErr:   errors.New("cannot connect"),
+		}
+
+		// Act
+		supervisor.SuperviseActor(actor)
+
+		// Assert
+		select {
+		case err := <-terminal:
+			if err.Error() != "cannot connect" {
+				t.Errorf("expected the PreStart error, got %v", err)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("expected the PreStart error to be escalated")
+		}
+	})
+}
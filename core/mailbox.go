@@ -0,0 +1,236 @@
+package core
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Overflow policies for bounded mailboxes
+const (
+	// MAILBOX_FAIL rejects new messages with ErrMailboxFull
+	MAILBOX_FAIL = iota
+	// MAILBOX_BLOCK blocks the sender until there is room
+	MAILBOX_BLOCK
+	// MAILBOX_DROP_NEWEST discards the message being sent
+	MAILBOX_DROP_NEWEST
+	// MAILBOX_DROP_OLDEST discards the oldest queued message to make room
+	MAILBOX_DROP_OLDEST
+)
+
+// Mailbox queues the messages of an actor until its run loop processes them
+type Mailbox interface {
+	// Enqueue adds a message, returning an error if it was not accepted
+	Enqueue(msg interface{}) error
+	// Dequeue removes the next message without blocking
+	Dequeue() (interface{}, bool)
+	// Ready is signalled after messages are enqueued
+	Ready() <-chan struct{}
+	// Len returns the number of queued messages
+	Len() int
+}
+
+// BoundedMailbox holds up to a fixed number of messages and applies an
+// overflow policy when it is full
+type BoundedMailbox struct {
+	messages     chan interface{}
+	ready        chan struct{}
+	policy       int
+	blockTimeout time.Duration
+	onOverflow   func(msg interface{})
+	closed       chan struct{} // Closed when the actor terminates
+	closeOnce    sync.Once
+}
+
+// NewBoundedMailbox creates a mailbox holding up to size messages
+func NewBoundedMailbox(size int, policy int) *BoundedMailbox {
+	return &BoundedMailbox{
+		messages: make(chan interface{}, size),
+		ready:    make(chan struct{}, 1),
+		policy:   policy,
+		closed:   make(chan struct{}),
+	}
+}
+
+// SetBlockTimeout bounds how long MAILBOX_BLOCK waits for room before
+// failing with ErrMailboxFull, 0 waits forever
+func (m *BoundedMailbox) SetBlockTimeout(timeout time.Duration) {
+	m.blockTimeout = timeout
+}
+
+// SetOverflowHandler sets where messages discarded by the drop policies or
+// rejected by MAILBOX_FAIL go. A BasicActor given a mailbox without a handler
+// sends them to its dead letters.
+func (m *BoundedMailbox) SetOverflowHandler(handler func(msg interface{})) {
+	m.onOverflow = handler
+}
+
+// Close releases the senders blocked by MAILBOX_BLOCK, which fail with
+// ErrActorStopped. BasicActor closes its mailbox when it terminates.
+func (m *BoundedMailbox) Close() {
+	m.closeOnce.Do(func() { close(m.closed) })
+}
+
+func (m *BoundedMailbox) Enqueue(msg interface{}) error {
+	select {
+	case m.messages <- msg:
+		m.signal()
+		return nil
+	default:
+	}
+
+	switch m.policy {
+	case MAILBOX_BLOCK:
+		return m.enqueueBlocking(msg)
+
+	case MAILBOX_DROP_NEWEST:
+		m.overflow(msg)
+		return nil
+
+	case MAILBOX_DROP_OLDEST:
+		for {
+			select {
+			case m.messages <- msg:
+				m.signal()
+				return nil
+			default:
+			}
+			select {
+			case oldest := <-m.messages:
+				m.overflow(oldest)
+			default:
+			}
+		}
+
+	default:
+		if m.onOverflow != nil {
+			m.onOverflow(msg)
+		}
+		return ErrMailboxFull
+	}
+}
+
+func (m *BoundedMailbox) enqueueBlocking(msg interface{}) error {
+	if m.blockTimeout <= 0 {
+		select {
+		case m.messages <- msg:
+			m.signal()
+			return nil
+		case <-m.closed:
+			return ErrActorStopped
+		}
+	}
+
+	timer := time.NewTimer(m.blockTimeout)
+	defer timer.Stop()
+	select {
+	case m.messages <- msg:
+		m.signal()
+		return nil
+	case <-m.closed:
+		return ErrActorStopped
+	case <-timer.C:
+		if m.onOverflow != nil {
+			m.onOverflow(msg)
+		}
+		return ErrMailboxFull
+	}
+}
+
+func (m *BoundedMailbox) overflow(msg interface{}) {
+	if m.onOverflow != nil {
+		m.onOverflow(msg)
+		return
+	}
+	fmt.Printf("Mailbox full, dropping message: %v\n", msg)
+}
+
+func (m *BoundedMailbox) signal() {
+	select {
+	case m.ready <- struct{}{}:
+	default:
+	}
+}
+
+func (m *BoundedMailbox) Dequeue() (interface{}, bool) {
+	select {
+	case msg := <-m.messages:
+		return msg, true
+	default:
+		return nil, false
+	}
+}
+
+func (m *BoundedMailbox) Ready() <-chan struct{} {
+	return m.ready
+}
+
+func (m *BoundedMailbox) Len() int {
+	return len(m.messages)
+}
+
+// UnboundedMailbox grows as needed and never rejects a message
+type UnboundedMailbox struct {
+	mu       sync.Mutex
+	messages []interface{} // Ring buffer
+	head     int
+	count    int
+	ready    chan struct{}
+}
+
+// NewUnboundedMailbox creates an empty unbounded mailbox
+func NewUnboundedMailbox() *UnboundedMailbox {
+	return &UnboundedMailbox{
+		messages: make([]interface{}, 16),
+		ready:    make(chan struct{}, 1),
+	}
+}
+
+func (m *UnboundedMailbox) Enqueue(msg interface{}) error {
+	m.mu.Lock()
+	if m.count == len(m.messages) {
+		m.grow()
+	}
+	m.messages[(m.head+m.count)%len(m.messages)] = msg
+	m.count++
+	m.mu.Unlock()
+
+	select {
+	case m.ready <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// grow doubles the ring buffer, callers must hold m.mu
+func (m *UnboundedMailbox) grow() {
+	messages := make([]interface{}, len(m.messages)*2)
+	for i := 0; i < m.count; i++ {
+		messages[i] = m.messages[(m.head+i)%len(m.messages)]
+	}
+	m.messages = messages
+	m.head = 0
+}
+
+func (m *UnboundedMailbox) Dequeue() (interface{}, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.count == 0 {
+		return nil, false
+	}
+	msg := m.messages[m.head]
+	m.messages[m.head] = nil
+	m.head = (m.head + 1) % len(m.messages)
+	m.count--
+	return msg, true
+}
+
+func (m *UnboundedMailbox) Ready() <-chan struct{} {
+	return m.ready
+}
+
+func (m *UnboundedMailbox) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.count
+}
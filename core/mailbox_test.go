@@ -0,0 +1,218 @@
+package core
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func dequeueAll(mailbox Mailbox) []interface{} {
+	messages := make([]interface{}, 0)
+	for {
+		msg, ok := mailbox.Dequeue()
+		if !ok {
+			return messages
+		}
+		messages = append(messages, msg)
+	}
+}
+
+// Test suite for Mailbox implementations
+func TestMailbox(t *testing.T) {
+
+	t.Run("TestBoundedMailboxFailPolicy", func(t *testing.T) {
+		// Arrange
+		var overflowed []interface{}
+		mailbox := NewBoundedMailbox(2, MAILBOX_FAIL)
+		mailbox.SetOverflowHandler(func(msg interface{}) {
+			overflowed = append(overflowed, msg)
+		})
+
+		// Act
+		mailbox.Enqueue(1)
+		mailbox.Enqueue(2)
+		err := mailbox.Enqueue(3)
+
+		// Assert
+		if !errors.Is(err, ErrMailboxFull) {
+			t.Errorf("expected ErrMailboxFull, got %v", err)
+		}
+		if len(overflowed) != 1 || overflowed[0] != 3 {
+			t.Errorf("expected the rejected message to be routed to the overflow handler, got %v", overflowed)
+		}
+	})
+
+	t.Run("TestBoundedMailboxDropNewest", func(t *testing.T) {
+		// Arrange
+		mailbox := NewBoundedMailbox(2, MAILBOX_DROP_NEWEST)
+
+		// Act
+		mailbox.Enqueue(1)
+		mailbox.Enqueue(2)
+		err := mailbox.Enqueue(3)
+
+		// Assert
+		messages := dequeueAll(mailbox)
+		if err != nil || len(messages) != 2 || messages[0] != 1 || messages[1] != 2 {
+			t.Errorf("expected [1 2] and no error, got %v (%v)", messages, err)
+		}
+	})
+
+	t.Run("TestBoundedMailboxDropOldest", func(t *testing.T) {
+		// Arrange
+		var dropped []interface{}
+		mailbox := NewBoundedMailbox(2, MAILBOX_DROP_OLDEST)
+		mailbox.SetOverflowHandler(func(msg interface{}) {
+			dropped = append(dropped, msg)
+		})
+
+		// Act
+		mailbox.Enqueue(1)
+		mailbox.Enqueue(2)
+		mailbox.Enqueue(3)
+
+		// Assert
+		messages := dequeueAll(mailbox)
+		if len(messages) != 2 || messages[0] != 2 || messages[1] != 3 {
+			t.Errorf("expected [2 3], got %v", messages)
+		}
+		if len(dropped) != 1 || dropped[0] != 1 {
+			t.Errorf("expected the oldest message to be dropped, got %v", dropped)
+		}
+	})
+
+	t.Run("TestBoundedMailboxBlocks", func(t *testing.T) {
+		// Arrange
+		mailbox := NewBoundedMailbox(1, MAILBOX_BLOCK)
+		mailbox.Enqueue(1)
+		enqueued := make(chan error, 1)
+
+		// Act
+		go func() {
+			enqueued <- mailbox.Enqueue(2)
+		}()
+
+		// Assert
+		select {
+		case <-enqueued:
+			t.Fatalf("expected the sender to block while the mailbox is full")
+		case <-time.After(50 * time.Millisecond):
+		}
+		mailbox.Dequeue()
+		select {
+		case err := <-enqueued:
+			if err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("expected the sender to be unblocked once there is room")
+		}
+	})
+
+	t.Run("TestBoundedMailboxBlockTimeout", func(t *testing.T) {
+		// Arrange
+		mailbox := NewBoundedMailbox(1, MAILBOX_BLOCK)
+		mailbox.SetBlockTimeout(20 * time.Millisecond)
+		mailbox.Enqueue(1)
+
+		// Act
+		err := mailbox.Enqueue(2)
+
+		// Assert
+		if !errors.Is(err, ErrMailboxFull) {
+			t.Errorf("expected ErrMailboxFull after the timeout, got %v", err)
+		}
+	})
+
+	t.Run("TestBlockedSenderReleasedOnStop", func(t *testing.T) {
+		// Arrange
+		deadLetters, letters := collectDeadLetters(t)
+		release := make(chan struct{})
+		actor := NewBasicActorWithMailbox("blocking-actor", NewBoundedMailbox(1, MAILBOX_BLOCK))
+		actor.SetDeadLetters(deadLetters)
+		actor.ReceiveFunc = func(result *ActorResult) *ActorResult {
+			<-release
+			return &ActorResult{}
+		}
+		actor.Start()
+		actor.SendMessage("processing")
+		actor.SendMessage("queued")
+		sent := make(chan error, 1)
+		go func() {
+			sent <- actor.tell("blocked")
+		}()
+
+		// Act
+		actor.Stop()
+		close(release)
+
+		// Assert
+		select {
+		case err := <-sent:
+			if !errors.Is(err, ErrActorStopped) {
+				t.Errorf("expected ErrActorStopped, got %v", err)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("expected the blocked sender to be released when the actor stops")
+		}
+		dropped := map[interface{}]bool{}
+		for i := 0; i < 2; i++ {
+			dropped[expectDeadLetter(t, letters).Message] = true
+		}
+		if !dropped["queued"] || !dropped["blocked"] {
+			t.Errorf("expected the queued and blocked messages as dead letters, got %v", dropped)
+		}
+	})
+
+	t.Run("TestUnboundedMailboxKeepsOrder", func(t *testing.T) {
+		// Arrange
+		mailbox := NewUnboundedMailbox()
+
+		// Act
+		for i := 0; i < 1000; i++ {
+			mailbox.Enqueue(i)
+			if i%3 == 0 {
+				mailbox.Enqueue(-1)
+				mailbox.Dequeue()
+			}
+		}
+
+		// Assert
+		if mailbox.Len() != 1000 {
+			t.Fatalf("expected 1000 queued messages, got %d", mailbox.Len())
+		}
+		previous := -1
+		for _, msg := range dequeueAll(mailbox) {
+			if msg.(int) == -1 {
+				continue
+			}
+			if msg.(int) <= previous {
+				t.Fatalf("expected messages in order, got %d after %d", msg, previous)
+			}
+			previous = msg.(int)
+		}
+	})
+
+	t.Run("TestActorWithUnboundedMailbox", func(t *testing.T) {
+		// Arrange
+		messages := 1000
+		var wg sync.WaitGroup
+		wg.Add(messages)
+		actor := NewBasicActorWithMailbox("unbounded-actor", NewUnboundedMailbox())
+		actor.ReceiveFunc = func(result *ActorResult) *ActorResult {
+			wg.Done()
+			return &ActorResult{}
+		}
+
+		// Act
+		for i := 0; i < messages; i++ {
+			actor.SendMessage(i)
+		}
+		actor.Start()
+		defer actor.Stop()
+
+		// Assert
+		wg.Wait()
+	})
+}
@@ -13,6 +13,7 @@ type MessageBroker interface {
 // InMemoryBroker is an in-memory implementation of the MessageBroker interface
 type InMemoryBroker struct {
 	subscribers map[string][]Actor
+	deadLetters *DeadLetters
 	mu          sync.RWMutex
 }
 
@@ -30,7 +31,10 @@ func (b *InMemoryBroker) Publish(topic string, msg interface{}) error {
 
 	actors, ok := b.subscribers[topic]
 	if !ok {
-		return fmt.Errorf("no subscribers for topic %s", topic)
+		if b.deadLetters != nil {
+			b.deadLetters.Publish(DeadLetter{Message: msg, Topic: topic, Reason: ErrNoSubscribers})
+		}
+		return fmt.Errorf("%w for topic %s", ErrNoSubscribers, topic)
 	}
 
 	for _, actor := range actors {
@@ -40,6 +44,14 @@ func (b *InMemoryBroker) Publish(topic string, msg interface{}) error {
 	return nil
 }
 
+// SetDeadLetters records messages published to topics without subscribers
+// in deadLetters
+func (b *InMemoryBroker) SetDeadLetters(deadLetters *DeadLetters) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.deadLetters = deadLetters
+}
+
 // Subscribe adds an actor to the list of subscribers for a given topic
 func (b *InMemoryBroker) Subscribe(topic string, actor Actor) error {
 	b.mu.Lock()
@@ -0,0 +1,62 @@
+package persistence_test
+
+import (
+	"testing"
+
+	"github.com/EndlessUpHill/goakka/core/persistence"
+	"github.com/EndlessUpHill/goakka/core/persistence/persistencetest"
+)
+
+func newFileStores(t *testing.T) (persistence.Journal, persistence.SnapshotStore) {
+	t.Helper()
+	dir := t.TempDir()
+	journal, err := persistence.NewFileJournal(dir+"/journal", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	snapshots, err := persistence.NewFileSnapshotStore(dir+"/snapshots", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return journal, snapshots
+}
+
+// Runs the shared suites against the built in stores
+func TestConformance(t *testing.T) {
+
+	t.Run("TestInMemoryJournal", func(t *testing.T) {
+		persistencetest.JournalSuite(t, func(t *testing.T) persistence.Journal {
+			return persistence.NewInMemoryJournal()
+		})
+	})
+
+	t.Run("TestFileJournal", func(t *testing.T) {
+		persistencetest.JournalSuite(t, func(t *testing.T) persistence.Journal {
+			journal, _ := newFileStores(t)
+			return journal
+		})
+	})
+
+	t.Run("TestInMemorySnapshotStore", func(t *testing.T) {
+		persistencetest.SnapshotStoreSuite(t, func(t *testing.T) persistence.SnapshotStore {
+			return persistence.NewInMemorySnapshotStore()
+		})
+	})
+
+	t.Run("TestFileSnapshotStore", func(t *testing.T) {
+		persistencetest.SnapshotStoreSuite(t, func(t *testing.T) persistence.SnapshotStore {
+			_, snapshots := newFileStores(t)
+			return snapshots
+		})
+	})
+
+	t.Run("TestInMemoryPersistentActor", func(t *testing.T) {
+		persistencetest.PersistentActorSuite(t, func(t *testing.T) (persistence.Journal, persistence.SnapshotStore) {
+			return persistence.NewInMemoryJournal(), persistence.NewInMemorySnapshotStore()
+		})
+	})
+
+	t.Run("TestFilePersistentActor", func(t *testing.T) {
+		persistencetest.PersistentActorSuite(t, newFileStores)
+	})
+}
@@ -0,0 +1,411 @@
+package persistence
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FileJournal stores the events of each persistence ID in its own file of
+// JSON lines under a directory, one line per write so a batch survives a
+// crash entirely or not at all, syncing every write to disk. It is meant for
+// a single process: the highest sequence numbers are cached in memory.
+type FileJournal struct {
+	dir        string
+	serializer Serializer
+	mu         sync.Mutex
+	highest    map[string]uint64
+}
+
+// fileRecord is a line of a journal file. A deleted record only keeps the
+// highest sequence number once every event was deleted.
+type fileRecord struct {
+	SequenceNr uint64    `json:"sequenceNr"`
+	Timestamp  time.Time `json:"timestamp"`
+	Payload    []byte    `json:"payload,omitempty"`
+	Tags       []string  `json:"tags,omitempty"`
+	Deleted    bool      `json:"deleted,omitempty"`
+}
+
+// fileBatch is a line of a journal file, holding the events of one Write
+type fileBatch struct {
+	Records []fileRecord `json:"records"`
+}
+
+// NewFileJournal creates a journal writing to dir, created if missing. A nil
+// serializer defaults to GobSerializer.
+func NewFileJournal(dir string, serializer Serializer) (*FileJournal, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	if serializer == nil {
+		serializer = GobSerializer{}
+	}
+	return &FileJournal{
+		dir:        dir,
+		serializer: serializer,
+		highest:    make(map[string]uint64),
+	}, nil
+}
+
+func (j *FileJournal) path(persistenceID string) string {
+	return filepath.Join(j.dir, url.PathEscape(persistenceID)+".journal")
+}
+
+func (j *FileJournal) Write(ctx context.Context, events []Event) error {
+	if len(events) == 0 {
+		return nil
+	}
+	persistenceID := events[0].PersistenceID
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	highest, err := j.highestSequenceNr(persistenceID)
+	if err != nil {
+		return err
+	}
+	if err := checkSequence(events, persistenceID, highest); err != nil {
+		return err
+	}
+
+	batch := fileBatch{Records: make([]fileRecord, len(events))}
+	for i, event := range events {
+		payload, err := j.serializer.Marshal(event.Payload)
+		if err != nil {
+			return fmt.Errorf("could not serialize event %d of %s: %w", event.SequenceNr, persistenceID, err)
+		}
+		batch.Records[i] = fileRecord{SequenceNr: event.SequenceNr, Timestamp: event.Timestamp, Payload: payload, Tags: event.Tags}
+	}
+	line, err := json.Marshal(batch)
+	if err != nil {
+		return err
+	}
+	file, err := os.OpenFile(j.path(persistenceID), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	if err := appendLine(file, append(line, '\n')); err != nil {
+		// The next access reads the file again, cutting off whatever the
+		// failed write left behind
+		delete(j.highest, persistenceID)
+		return err
+	}
+	j.highest[persistenceID] = events[len(events)-1].SequenceNr
+	return nil
+}
+
+// appendLine writes line at the end of file and syncs it. A failed write is
+// truncated away, so later writes do not follow a torn line.
+func appendLine(file *os.File, line []byte) error {
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	_, err = file.Write(line)
+	if err == nil {
+		err = file.Sync()
+	}
+	if err != nil {
+		if errTruncate := file.Truncate(info.Size()); errTruncate != nil {
+			fmt.Printf("Could not truncate failed write to journal %s: %v\n", file.Name(), errTruncate)
+		}
+		return err
+	}
+	return nil
+}
+
+func (j *FileJournal) Replay(ctx context.Context, persistenceID string, fromSequenceNr, toSequenceNr uint64, fn func(Event) error) error {
+	j.mu.Lock()
+	records, _, err := readRecords(j.path(persistenceID))
+	j.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	for _, record := range records {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if record.Deleted || record.SequenceNr < fromSequenceNr {
+			continue
+		}
+		if record.SequenceNr > toSequenceNr {
+			return nil
+		}
+		payload, err := j.serializer.Unmarshal(record.Payload)
+		if err != nil {
+			return fmt.Errorf("could not deserialize event %d of %s: %w", record.SequenceNr, persistenceID, err)
+		}
+		if err := fn(Event{PersistenceID: persistenceID, SequenceNr: record.SequenceNr, Payload: payload, Tags: record.Tags, Timestamp: record.Timestamp}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (j *FileJournal) HighestSequenceNr(ctx context.Context, persistenceID string) (uint64, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.highestSequenceNr(persistenceID)
+}
+
+// highestSequenceNr returns the cached highest sequence number, reading the
+// file on first use. A line torn by a crash while writing is cut off so the
+// next write starts on a fresh line. Must be called with j.mu held.
+func (j *FileJournal) highestSequenceNr(persistenceID string) (uint64, error) {
+	if highest, ok := j.highest[persistenceID]; ok {
+		return highest, nil
+	}
+	path := j.path(persistenceID)
+	records, size, err := readRecords(path)
+	if err != nil {
+		return 0, err
+	}
+	if info, err := os.Stat(path); err == nil && info.Size() > size {
+		fmt.Printf("Truncating torn write at the end of journal %s\n", path)
+		if err := os.Truncate(path, size); err != nil {
+			return 0, err
+		}
+	}
+	var highest uint64
+	if len(records) > 0 {
+		highest = records[len(records)-1].SequenceNr
+	}
+	j.highest[persistenceID] = highest
+	return highest, nil
+}
+
+func (j *FileJournal) DeleteTo(ctx context.Context, persistenceID string, toSequenceNr uint64) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	highest, err := j.highestSequenceNr(persistenceID)
+	if err != nil || highest == 0 {
+		return err
+	}
+	records, _, err := readRecords(j.path(persistenceID))
+	if err != nil {
+		return err
+	}
+
+	kept := records[:0]
+	for _, record := range records {
+		if record.SequenceNr > toSequenceNr && !record.Deleted {
+			kept = append(kept, record)
+		}
+	}
+	if len(kept) == 0 {
+		kept = append(kept, fileRecord{SequenceNr: highest, Timestamp: time.Now(), Deleted: true})
+	}
+	line, err := json.Marshal(fileBatch{Records: kept})
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(j.path(persistenceID), append(line, '\n'))
+}
+
+// readRecords reads the records of the complete lines of a journal file and
+// returns the size they take. A missing file has no records.
+func readRecords(path string) ([]fileRecord, int64, error) {
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, 0, nil
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+	defer file.Close()
+
+	var records []fileRecord
+	var size int64
+	reader := bufio.NewReader(file)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err == io.EOF {
+			// An incomplete last line is a write that did not finish
+			return records, size, nil
+		}
+		if err != nil {
+			return nil, 0, err
+		}
+		var batch fileBatch
+		if err := json.Unmarshal(line, &batch); err != nil {
+			return nil, 0, fmt.Errorf("corrupt journal %s at offset %d: %w", path, size, err)
+		}
+		records = append(records, batch.Records...)
+		size += int64(len(line))
+	}
+}
+
+// writeFileAtomic replaces path with data, so readers and crashes never see
+// a partially written file
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// FileSnapshotStore stores each snapshot in its own file, under a directory
+// per persistence ID
+type FileSnapshotStore struct {
+	dir        string
+	serializer Serializer
+	mu         sync.Mutex
+}
+
+// snapshotFile is the content of a snapshot file
+type snapshotFile struct {
+	PersistenceID string    `json:"persistenceId"`
+	SequenceNr    uint64    `json:"sequenceNr"`
+	Timestamp     time.Time `json:"timestamp"`
+	State         []byte    `json:"state"`
+}
+
+const (
+	snapshotExtension = ".snapshot"
+	// snapshotDirExtension keeps the directory of a persistence ID such as
+	// ".." inside the store
+	snapshotDirExtension = ".snapshots"
+)
+
+// NewFileSnapshotStore creates a snapshot store writing to dir, created if
+// missing. A nil serializer defaults to GobSerializer.
+func NewFileSnapshotStore(dir string, serializer Serializer) (*FileSnapshotStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	if serializer == nil {
+		serializer = GobSerializer{}
+	}
+	return &FileSnapshotStore{
+		dir:        dir,
+		serializer: serializer,
+	}, nil
+}
+
+func (s *FileSnapshotStore) path(persistenceID string) string {
+	return filepath.Join(s.dir, url.PathEscape(persistenceID)+snapshotDirExtension)
+}
+
+func (s *FileSnapshotStore) Save(ctx context.Context, snapshot Snapshot) error {
+	state, err := s.serializer.Marshal(snapshot.State)
+	if err != nil {
+		return fmt.Errorf("could not serialize snapshot %d of %s: %w", snapshot.SequenceNr, snapshot.PersistenceID, err)
+	}
+	data, err := json.Marshal(snapshotFile{
+		PersistenceID: snapshot.PersistenceID,
+		SequenceNr:    snapshot.SequenceNr,
+		Timestamp:     snapshot.Timestamp,
+		State:         state,
+	})
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	dir := s.path(snapshot.PersistenceID)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	// Zero padded names sort by sequence number
+	name := fmt.Sprintf("%020d%s", snapshot.SequenceNr, snapshotExtension)
+	return writeFileAtomic(filepath.Join(dir, name), data)
+}
+
+func (s *FileSnapshotStore) Load(ctx context.Context, persistenceID string) (Snapshot, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sequenceNrs, err := s.sequenceNrs(persistenceID)
+	if err != nil || len(sequenceNrs) == 0 {
+		return Snapshot{}, false, err
+	}
+
+	latest := sequenceNrs[len(sequenceNrs)-1]
+	data, err := os.ReadFile(filepath.Join(s.path(persistenceID), fmt.Sprintf("%020d%s", latest, snapshotExtension)))
+	if err != nil {
+		return Snapshot{}, false, err
+	}
+	var file snapshotFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return Snapshot{}, false, fmt.Errorf("corrupt snapshot %d of %s: %w", latest, persistenceID, err)
+	}
+	state, err := s.serializer.Unmarshal(file.State)
+	if err != nil {
+		return Snapshot{}, false, fmt.Errorf("could not deserialize snapshot %d of %s: %w", latest, persistenceID, err)
+	}
+	return Snapshot{
+		PersistenceID: persistenceID,
+		SequenceNr:    file.SequenceNr,
+		State:         state,
+		Timestamp:     file.Timestamp,
+	}, true, nil
+}
+
+func (s *FileSnapshotStore) DeleteTo(ctx context.Context, persistenceID string, toSequenceNr uint64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sequenceNrs, err := s.sequenceNrs(persistenceID)
+	if err != nil {
+		return err
+	}
+	for _, sequenceNr := range sequenceNrs {
+		if sequenceNr > toSequenceNr {
+			break
+		}
+		if err := os.Remove(filepath.Join(s.path(persistenceID), fmt.Sprintf("%020d%s", sequenceNr, snapshotExtension))); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sequenceNrs lists the sequence numbers of the snapshots of persistenceID
+// in ascending order. Must be called with s.mu held.
+func (s *FileSnapshotStore) sequenceNrs(persistenceID string) ([]uint64, error) {
+	entries, err := os.ReadDir(s.path(persistenceID))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var sequenceNrs []uint64
+	for _, entry := range entries {
+		name := entry.Name()
+		if !strings.HasSuffix(name, snapshotExtension) {
+			continue
+		}
+		sequenceNr, err := strconv.ParseUint(strings.TrimSuffix(name, snapshotExtension), 10, 64)
+		if err != nil {
+			continue
+		}
+		sequenceNrs = append(sequenceNrs, sequenceNr)
+	}
+	sort.Slice(sequenceNrs, func(i, j int) bool { return sequenceNrs[i] < sequenceNrs[j] })
+	return sequenceNrs, nil
+}
@@ -0,0 +1,123 @@
+package persistence
+
+import (
+	"context"
+	"encoding/gob"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type incremented struct {
+	By int
+}
+
+func init() {
+	gob.Register(incremented{})
+	gob.Register(map[string]int{})
+}
+
+func events(persistenceID string, from uint64, payloads ...interface{}) []Event {
+	batch := make([]Event, len(payloads))
+	for i, payload := range payloads {
+		batch[i] = Event{
+			PersistenceID: persistenceID,
+			SequenceNr:    from + uint64(i),
+			Payload:       payload,
+			Timestamp:     time.Now(),
+		}
+	}
+	return batch
+}
+
+func replayAll(t *testing.T, journal Journal, persistenceID string, from, to uint64) []interface{} {
+	t.Helper()
+	var payloads []interface{}
+	err := journal.Replay(context.Background(), persistenceID, from, to, func(event Event) error {
+		payloads = append(payloads, event.Payload)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return payloads
+}
+
+func newFileJournal(t *testing.T, dir string) Journal {
+	t.Helper()
+	journal, err := NewFileJournal(dir, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return journal
+}
+
+// Test suite for the file journal, the shared journal suite runs in
+// conformance_test.go
+func TestFileJournal(t *testing.T) {
+
+	t.Run("TestFileJournalReopen", func(t *testing.T) {
+		// Arrange
+		dir := t.TempDir()
+		newFileJournal(t, dir).Write(context.Background(), events("orders/42", 1, incremented{By: 1}, "created"))
+
+		// Act
+		journal := newFileJournal(t, dir)
+		highest, _ := journal.HighestSequenceNr(context.Background(), "orders/42")
+
+		// Assert
+		if highest != 2 {
+			t.Errorf("expected the events to survive reopening, got highest %d", highest)
+		}
+		expected := []interface{}{incremented{By: 1}, "created"}
+		if got := replayAll(t, journal, "orders/42", 1, 2); !reflect.DeepEqual(got, expected) {
+			t.Errorf("expected %v, got %v", expected, got)
+		}
+	})
+
+	t.Run("TestFileJournalTornWrite", func(t *testing.T) {
+		// Arrange
+		dir := t.TempDir()
+		newFileJournal(t, dir).Write(context.Background(), events("counter", 1, incremented{By: 1}))
+		file, _ := os.OpenFile(dir+"/counter.journal", os.O_APPEND|os.O_WRONLY, 0o644)
+		file.WriteString(`{"sequenceNr":2,"paylo`)
+		file.Close()
+
+		// Act
+		journal := newFileJournal(t, dir)
+		err := journal.Write(context.Background(), events("counter", 2, incremented{By: 2}))
+
+		// Assert
+		if err != nil {
+			t.Fatalf("expected the torn write to be discarded, got %v", err)
+		}
+		expected := []interface{}{incremented{By: 1}, incremented{By: 2}}
+		if got := replayAll(t, journal, "counter", 1, 10); !reflect.DeepEqual(got, expected) {
+			t.Errorf("expected %v, got %v", expected, got)
+		}
+	})
+
+	t.Run("TestFileJournalTornBatch", func(t *testing.T) {
+		// Arrange
+		dir := t.TempDir()
+		journal := newFileJournal(t, dir)
+		journal.Write(context.Background(), events("counter", 1, incremented{By: 1}))
+		journal.Write(context.Background(), events("counter", 2, incremented{By: 2}, incremented{By: 3}))
+		info, _ := os.Stat(dir + "/counter.journal")
+		os.Truncate(dir+"/counter.journal", info.Size()-10)
+
+		// Act
+		journal = newFileJournal(t, dir)
+		highest, _ := journal.HighestSequenceNr(context.Background(), "counter")
+
+		// Assert
+		if highest != 1 {
+			t.Errorf("expected the torn batch to be discarded entirely, got highest %d", highest)
+		}
+		expected := []interface{}{incremented{By: 1}}
+		if got := replayAll(t, journal, "counter", 1, 10); !reflect.DeepEqual(got, expected) {
+			t.Errorf("expected %v, got %v", expected, got)
+		}
+	})
+}
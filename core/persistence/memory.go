@@ -0,0 +1,121 @@
+package persistence
+
+import (
+	"context"
+	"sort"
+	"sync"
+)
+
+// InMemoryJournal keeps events in memory, for tests and actors whose state
+// only has to survive restarts within the process
+type InMemoryJournal struct {
+	mu      sync.RWMutex
+	events  map[string][]Event
+	highest map[string]uint64
+}
+
+func NewInMemoryJournal() *InMemoryJournal {
+	return &InMemoryJournal{
+		events:  make(map[string][]Event),
+		highest: make(map[string]uint64),
+	}
+}
+
+func (j *InMemoryJournal) Write(ctx context.Context, events []Event) error {
+	if len(events) == 0 {
+		return nil
+	}
+	persistenceID := events[0].PersistenceID
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if err := checkSequence(events, persistenceID, j.highest[persistenceID]); err != nil {
+		return err
+	}
+	j.events[persistenceID] = append(j.events[persistenceID], events...)
+	j.highest[persistenceID] = events[len(events)-1].SequenceNr
+	return nil
+}
+
+func (j *InMemoryJournal) Replay(ctx context.Context, persistenceID string, fromSequenceNr, toSequenceNr uint64, fn func(Event) error) error {
+	j.mu.RLock()
+	events := j.events[persistenceID]
+	j.mu.RUnlock()
+	for _, event := range events {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if event.SequenceNr < fromSequenceNr {
+			continue
+		}
+		if event.SequenceNr > toSequenceNr {
+			return nil
+		}
+		if err := fn(event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (j *InMemoryJournal) HighestSequenceNr(ctx context.Context, persistenceID string) (uint64, error) {
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+	return j.highest[persistenceID], nil
+}
+
+func (j *InMemoryJournal) DeleteTo(ctx context.Context, persistenceID string, toSequenceNr uint64) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	events := j.events[persistenceID]
+	i := sort.Search(len(events), func(i int) bool { return events[i].SequenceNr > toSequenceNr })
+	// Copy so replays running on the old slice are not affected
+	j.events[persistenceID] = append([]Event(nil), events[i:]...)
+	return nil
+}
+
+// InMemorySnapshotStore keeps snapshots in memory
+type InMemorySnapshotStore struct {
+	mu        sync.RWMutex
+	snapshots map[string][]Snapshot
+}
+
+func NewInMemorySnapshotStore() *InMemorySnapshotStore {
+	return &InMemorySnapshotStore{
+		snapshots: make(map[string][]Snapshot),
+	}
+}
+
+func (s *InMemorySnapshotStore) Save(ctx context.Context, snapshot Snapshot) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	snapshots := s.snapshots[snapshot.PersistenceID]
+	i := sort.Search(len(snapshots), func(i int) bool { return snapshots[i].SequenceNr >= snapshot.SequenceNr })
+	if i < len(snapshots) && snapshots[i].SequenceNr == snapshot.SequenceNr {
+		snapshots[i] = snapshot
+		return nil
+	}
+	snapshots = append(snapshots, Snapshot{})
+	copy(snapshots[i+1:], snapshots[i:])
+	snapshots[i] = snapshot
+	s.snapshots[snapshot.PersistenceID] = snapshots
+	return nil
+}
+
+func (s *InMemorySnapshotStore) Load(ctx context.Context, persistenceID string) (Snapshot, bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	snapshots := s.snapshots[persistenceID]
+	if len(snapshots) == 0 {
+		return Snapshot{}, false, nil
+	}
+	return snapshots[len(snapshots)-1], true, nil
+}
+
+func (s *InMemorySnapshotStore) DeleteTo(ctx context.Context, persistenceID string, toSequenceNr uint64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	snapshots := s.snapshots[persistenceID]
+	i := sort.Search(len(snapshots), func(i int) bool { return snapshots[i].SequenceNr > toSequenceNr })
+	s.snapshots[persistenceID] = append([]Snapshot(nil), snapshots[i:]...)
+	return nil
+}
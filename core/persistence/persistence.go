@@ -0,0 +1,103 @@
+// Package persistence adds event sourcing to core actors: a PersistentActor
+// records the events changing its state in a Journal before applying them,
+// and rebuilds that state by replaying them, optionally starting from a
+// snapshot kept in a SnapshotStore.
+package persistence
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var (
+	// ErrSequenceConflict is returned by Journal.Write when the events do not
+	// directly follow the highest sequence number stored, usually because
+	// another writer persisted events for the same persistence ID
+	ErrSequenceConflict = errors.New("sequence number conflict")
+	// ErrNoSnapshotStore is returned by SaveSnapshot on actors without a
+	// snapshot store or SnapshotState function
+	ErrNoSnapshotStore = errors.New("no snapshot store configured")
+)
+
+// Event is a persisted event of the actor identified by PersistenceID.
+// Sequence numbers start at 1 and have no gaps. Tags group events across
+// persistence IDs, see EventsByTagQuery.
+type Event struct {
+	PersistenceID string
+	SequenceNr    uint64
+	Payload       interface{}
+	Tags          []string
+	Timestamp     time.Time
+}
+
+// Tagged wraps an event passed to PersistentActor.Persist to store it with
+// tags. The tags are recorded in the journal, ApplyEvent gets Payload.
+type Tagged struct {
+	Payload interface{}
+	Tags    []string
+}
+
+// Journal stores the events of persistent actors. Implementations must be
+// safe for concurrent use.
+type Journal interface {
+	// Write appends events of a single persistence ID atomically. The first
+	// event must follow the highest sequence number stored, otherwise
+	// nothing is written and ErrSequenceConflict is returned.
+	Write(ctx context.Context, events []Event) error
+	// Replay calls fn in order for the events of persistenceID numbered
+	// from fromSequenceNr to toSequenceNr inclusive, stopping at the first
+	// error returned by fn
+	Replay(ctx context.Context, persistenceID string, fromSequenceNr, toSequenceNr uint64, fn func(Event) error) error
+	// HighestSequenceNr returns the sequence number of the last event
+	// written for persistenceID, including deleted ones, 0 if none
+	HighestSequenceNr(ctx context.Context, persistenceID string) (uint64, error)
+	// DeleteTo removes the events of persistenceID up to toSequenceNr
+	// inclusive, e.g. once a snapshot covers them
+	DeleteTo(ctx context.Context, persistenceID string, toSequenceNr uint64) error
+}
+
+// EventsByTagQuery is implemented by journals able to find the events with
+// a tag, e.g. to build projections. Offsets order events across persistence
+// IDs in the order they were written.
+type EventsByTagQuery interface {
+	// EventsByTag calls fn in order for the events tagged with tag whose
+	// offset is at least fromOffset, stopping at the first error returned
+	// by fn
+	EventsByTag(ctx context.Context, tag string, fromOffset uint64, fn func(offset uint64, event Event) error) error
+}
+
+// Snapshot is the state of the actor identified by PersistenceID after
+// applying the events up to SequenceNr
+type Snapshot struct {
+	PersistenceID string
+	SequenceNr    uint64
+	State         interface{}
+	Timestamp     time.Time
+}
+
+// SnapshotStore stores snapshots of persistent actors. Implementations must
+// be safe for concurrent use.
+type SnapshotStore interface {
+	// Save stores a snapshot, replacing one with the same sequence number
+	Save(ctx context.Context, snapshot Snapshot) error
+	// Load returns the latest snapshot of persistenceID, false if none
+	Load(ctx context.Context, persistenceID string) (Snapshot, bool, error)
+	// DeleteTo removes the snapshots of persistenceID up to toSequenceNr
+	// inclusive
+	DeleteTo(ctx context.Context, persistenceID string, toSequenceNr uint64) error
+}
+
+// checkSequence reports whether events belong to persistenceID and follow
+// highest without gaps
+func checkSequence(events []Event, persistenceID string, highest uint64) error {
+	for i, event := range events {
+		if event.PersistenceID != persistenceID {
+			return errors.New("events of several persistence IDs written at once")
+		}
+		if event.SequenceNr != highest+uint64(i)+1 {
+			return ErrSequenceConflict
+		}
+	}
+	return nil
+}
@@ -0,0 +1,402 @@
+// Package persistencetest checks that journals and snapshot stores behave
+// the way PersistentActor relies on. Every backend runs the same suites
+// against its own constructor, e.g.
+//
+//	func TestJournal(t *testing.T) {
+//		persistencetest.JournalSuite(t, func(t *testing.T) persistence.Journal {
+//			return NewMyJournal(t.TempDir())
+//		})
+//	}
+package persistencetest
+
+import (
+	"context"
+	"encoding/gob"
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/EndlessUpHill/goakka/core"
+	"github.com/EndlessUpHill/goakka/core/persistence"
+)
+
+// Incremented is the event written by the suites
+type Incremented struct {
+	By int
+}
+
+func init() {
+	gob.Register(Incremented{})
+}
+
+// Events builds events of persistenceID numbered from from, one per payload
+func Events(persistenceID string, from uint64, payloads ...interface{}) []persistence.Event {
+	batch := make([]persistence.Event, len(payloads))
+	for i, payload := range payloads {
+		batch[i] = persistence.Event{
+			PersistenceID: persistenceID,
+			SequenceNr:    from + uint64(i),
+			Payload:       payload,
+			Timestamp:     time.Now(),
+		}
+	}
+	return batch
+}
+
+// ReplayAll returns the events of persistenceID numbered from from to to
+func ReplayAll(t *testing.T, journal persistence.Journal, persistenceID string, from, to uint64) []persistence.Event {
+	t.Helper()
+	var replayed []persistence.Event
+	err := journal.Replay(context.Background(), persistenceID, from, to, func(event persistence.Event) error {
+		replayed = append(replayed, event)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return replayed
+}
+
+func payloads(events []persistence.Event) []interface{} {
+	values := make([]interface{}, len(events))
+	for i, event := range events {
+		values[i] = event.Payload
+	}
+	return values
+}
+
+// JournalSuite runs the behavior every persistence.Journal must have, plus
+// the tag queries of journals implementing persistence.EventsByTagQuery.
+// newJournal must return an empty journal.
+func JournalSuite(t *testing.T, newJournal func(t *testing.T) persistence.Journal) {
+	ctx := context.Background()
+
+	t.Run("TestWriteAndReplay", func(t *testing.T) {
+		// Arrange
+		journal := newJournal(t)
+		written := Events("counter", 1, Incremented{By: 1}, Incremented{By: 2}, "closed")
+		journal.Write(ctx, written[:2])
+		journal.Write(ctx, Events("other", 1, "unrelated"))
+
+		// Act
+		err := journal.Write(ctx, written[2:])
+		highest, errHighest := journal.HighestSequenceNr(ctx, "counter")
+
+		// Assert
+		if err != nil || errHighest != nil || highest != 3 {
+			t.Errorf("expected highest sequence number 3, got %d (%v, %v)", highest, err, errHighest)
+		}
+		replayed := ReplayAll(t, journal, "counter", 2, math.MaxUint64)
+		expected := []interface{}{Incremented{By: 2}, "closed"}
+		if got := payloads(replayed); !reflect.DeepEqual(got, expected) {
+			t.Fatalf("expected %v, got %v", expected, got)
+		}
+		if replayed[1].SequenceNr != 3 || replayed[1].PersistenceID != "counter" {
+			t.Errorf("expected event 3 of counter, got %+v", replayed[1])
+		}
+		if replayed[1].Timestamp.UnixNano() != written[2].Timestamp.UnixNano() {
+			t.Errorf("expected the timestamp to be kept, got %v", replayed[1].Timestamp)
+		}
+		if got := payloads(ReplayAll(t, journal, "counter", 1, 1)); !reflect.DeepEqual(got, []interface{}{Incremented{By: 1}}) {
+			t.Errorf("expected only the first event, got %v", got)
+		}
+	})
+
+	t.Run("TestTags", func(t *testing.T) {
+		// Arrange
+		journal := newJournal(t)
+		written := Events("counter", 1, Incremented{By: 1}, Incremented{By: 2})
+		written[0].Tags = []string{"counters", "large"}
+
+		// Act
+		err := journal.Write(ctx, written)
+
+		// Assert
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		replayed := ReplayAll(t, journal, "counter", 1, math.MaxUint64)
+		if len(replayed) != 2 || !reflect.DeepEqual(replayed[0].Tags, []string{"counters", "large"}) {
+			t.Errorf("expected the tags to be stored, got %+v", replayed)
+		}
+		if len(replayed) == 2 && len(replayed[1].Tags) != 0 {
+			t.Errorf("expected no tags on the untagged event, got %v", replayed[1].Tags)
+		}
+	})
+
+	t.Run("TestSequenceConflict", func(t *testing.T) {
+		// Arrange
+		journal := newJournal(t)
+		journal.Write(ctx, Events("counter", 1, Incremented{By: 1}))
+
+		// Act
+		errDuplicate := journal.Write(ctx, Events("counter", 1, Incremented{By: 9}))
+		errGap := journal.Write(ctx, Events("counter", 3, Incremented{By: 9}))
+		errBatch := journal.Write(ctx, []persistence.Event{Events("counter", 2, Incremented{By: 9})[0], Events("counter", 4, Incremented{By: 9})[0]})
+
+		// Assert
+		for _, err := range []error{errDuplicate, errGap, errBatch} {
+			if !errors.Is(err, persistence.ErrSequenceConflict) {
+				t.Errorf("expected ErrSequenceConflict, got %v", err)
+			}
+		}
+		if got := ReplayAll(t, journal, "counter", 1, math.MaxUint64); len(got) != 1 {
+			t.Errorf("expected rejected writes to leave the journal unchanged, got %v", payloads(got))
+		}
+	})
+
+	t.Run("TestReplayLargeJournal", func(t *testing.T) {
+		// Arrange
+		journal := newJournal(t)
+		values := make([]interface{}, 1200)
+		for i := range values {
+			values[i] = Incremented{By: i}
+		}
+		journal.Write(ctx, Events("counter", 1, values...))
+
+		// Act
+		replayed := ReplayAll(t, journal, "counter", 1, math.MaxUint64)
+
+		// Assert
+		if len(replayed) != len(values) {
+			t.Fatalf("expected %d events, got %d", len(values), len(replayed))
+		}
+		for i, event := range replayed {
+			if event.SequenceNr != uint64(i+1) {
+				t.Fatalf("expected event %d in order, got %d", i+1, event.SequenceNr)
+			}
+		}
+	})
+
+	t.Run("TestDeleteTo", func(t *testing.T) {
+		// Arrange
+		journal := newJournal(t)
+		journal.Write(ctx, Events("counter", 1, Incremented{By: 1}, Incremented{By: 2}, Incremented{By: 3}))
+
+		// Act
+		errPartial := journal.DeleteTo(ctx, "counter", 2)
+		remaining := payloads(ReplayAll(t, journal, "counter", 1, math.MaxUint64))
+		errAll := journal.DeleteTo(ctx, "counter", 3)
+		highest, _ := journal.HighestSequenceNr(ctx, "counter")
+
+		// Assert
+		if errPartial != nil || errAll != nil {
+			t.Fatalf("unexpected errors: %v, %v", errPartial, errAll)
+		}
+		if !reflect.DeepEqual(remaining, []interface{}{Incremented{By: 3}}) {
+			t.Errorf("expected only the last event to remain, got %v", remaining)
+		}
+		if got := ReplayAll(t, journal, "counter", 1, math.MaxUint64); len(got) != 0 {
+			t.Errorf("expected every event to be deleted, got %v", payloads(got))
+		}
+		if highest != 3 {
+			t.Errorf("expected deleted events to keep counting, got %d", highest)
+		}
+		if err := journal.Write(ctx, Events("counter", 4, Incremented{By: 4})); err != nil {
+			t.Errorf("expected writes to continue after the deleted events, got %v", err)
+		}
+	})
+
+	t.Run("TestEventsByTag", func(t *testing.T) {
+		// Arrange
+		journal := newJournal(t)
+		query, ok := journal.(persistence.EventsByTagQuery)
+		if !ok {
+			t.Skip("journal does not implement EventsByTagQuery")
+		}
+		first := Events("counter-1", 1, Incremented{By: 10}, Incremented{By: 20})
+		first[0].Tags = []string{"counters", "large"}
+		first[1].Tags = []string{"counters"}
+		second := Events("counter-2", 1, Incremented{By: 5}, "closed")
+		second[0].Tags = []string{"counters"}
+		journal.Write(ctx, first)
+		journal.Write(ctx, second)
+		byTag := func(tag string, from uint64) ([]uint64, []persistence.Event) {
+			var offsets []uint64
+			var tagged []persistence.Event
+			err := query.EventsByTag(ctx, tag, from, func(offset uint64, event persistence.Event) error {
+				offsets = append(offsets, offset)
+				tagged = append(tagged, event)
+				return nil
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			return offsets, tagged
+		}
+
+		// Act
+		offsets, tagged := byTag("counters", 0)
+		_, resumed := byTag("counters", offsets[len(offsets)-1])
+		journal.DeleteTo(ctx, "counter-1", 2)
+		_, remaining := byTag("counters", 0)
+		_, large := byTag("large", 0)
+
+		// Assert
+		if len(tagged) != 3 || tagged[0].PersistenceID != "counter-1" || tagged[2].Payload != (Incremented{By: 5}) {
+			t.Fatalf("expected the three tagged events in write order, got %+v", tagged)
+		}
+		if !reflect.DeepEqual(tagged[0].Tags, []string{"counters", "large"}) {
+			t.Errorf("expected the events to keep their tags, got %v", tagged[0].Tags)
+		}
+		if offsets[0] >= offsets[1] || offsets[1] >= offsets[2] {
+			t.Errorf("expected increasing offsets, got %v", offsets)
+		}
+		if len(resumed) != 1 || resumed[0].PersistenceID != "counter-2" {
+			t.Errorf("expected to resume from the last offset, got %+v", resumed)
+		}
+		if len(remaining) != 1 || len(large) != 0 {
+			t.Errorf("expected deleted events to disappear from tag queries, got %+v and %+v", remaining, large)
+		}
+	})
+}
+
+func snapshot(persistenceID string, sequenceNr uint64, state interface{}) persistence.Snapshot {
+	return persistence.Snapshot{PersistenceID: persistenceID, SequenceNr: sequenceNr, State: state, Timestamp: time.Now()}
+}
+
+// SnapshotStoreSuite runs the behavior every persistence.SnapshotStore must
+// have. newStore must return an empty store.
+func SnapshotStoreSuite(t *testing.T, newStore func(t *testing.T) persistence.SnapshotStore) {
+	ctx := context.Background()
+
+	t.Run("TestLoadLatest", func(t *testing.T) {
+		// Arrange
+		store := newStore(t)
+		store.Save(ctx, snapshot("counter", 10, 10))
+		store.Save(ctx, snapshot("counter", 20, 25))
+		store.Save(ctx, snapshot("counter", 5, 5))
+		store.Save(ctx, snapshot("other", 30, 99))
+
+		// Act
+		latest, ok, err := store.Load(ctx, "counter")
+		_, okMissing, errMissing := store.Load(ctx, "missing")
+
+		// Assert
+		if err != nil || !ok || latest.SequenceNr != 20 || latest.State != 25 || latest.PersistenceID != "counter" {
+			t.Errorf("expected the snapshot at 20, got %+v (%v)", latest, err)
+		}
+		if okMissing || errMissing != nil {
+			t.Errorf("expected no snapshot for an unknown persistence ID, got %v", errMissing)
+		}
+	})
+
+	t.Run("TestSaveReplaces", func(t *testing.T) {
+		// Arrange
+		store := newStore(t)
+		store.Save(ctx, snapshot("counter", 10, 10))
+
+		// Act
+		store.Save(ctx, snapshot("counter", 10, 11))
+		latest, _, _ := store.Load(ctx, "counter")
+
+		// Assert
+		if latest.State != 11 {
+			t.Errorf("expected the snapshot to be replaced, got %v", latest.State)
+		}
+	})
+
+	t.Run("TestDeleteTo", func(t *testing.T) {
+		// Arrange
+		store := newStore(t)
+		store.Save(ctx, snapshot("counter", 10, 10))
+		store.Save(ctx, snapshot("counter", 20, 20))
+
+		// Act
+		errPartial := store.DeleteTo(ctx, "counter", 15)
+		remaining, okPartial, _ := store.Load(ctx, "counter")
+		errAll := store.DeleteTo(ctx, "counter", 20)
+		_, okAll, _ := store.Load(ctx, "counter")
+
+		// Assert
+		if errPartial != nil || errAll != nil {
+			t.Fatalf("unexpected errors: %v, %v", errPartial, errAll)
+		}
+		if !okPartial || remaining.SequenceNr != 20 {
+			t.Errorf("expected the later snapshot to remain, got %+v", remaining)
+		}
+		if okAll {
+			t.Errorf("expected every snapshot to be deleted")
+		}
+	})
+}
+
+// newCounter creates a persistent actor summing Incremented events, tagging
+// those of 20 or more with "large"
+func newCounter(journal persistence.Journal, snapshots persistence.SnapshotStore) *persistence.PersistentActor {
+	total := 0
+	counter := persistence.NewPersistentActor("counter", journal)
+	counter.SetSnapshotStore(snapshots)
+	counter.SetSnapshotInterval(2)
+	counter.ApplyEvent = func(event interface{}) { total += event.(Incremented).By }
+	counter.SnapshotState = func() interface{} { return total }
+	counter.RestoreSnapshot = func(state interface{}) { total = state.(int) }
+	counter.ReceiveCommand = func(result *core.ActorResult) *core.ActorResult {
+		if by, ok := result.Message.(int); ok {
+			event := interface{}(Incremented{By: by})
+			if by >= 20 {
+				event = persistence.Tagged{Payload: event, Tags: []string{"large"}}
+			}
+			if err := counter.Persist(event); err != nil {
+				return &core.ActorResult{Error: err}
+			}
+		}
+		result.Reply(total)
+		return &core.ActorResult{}
+	}
+	return counter
+}
+
+func ask(t *testing.T, actor core.Actor, msg interface{}) interface{} {
+	t.Helper()
+	future, err := core.Ask(context.Background(), actor, msg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	reply, err := future.Await(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return reply
+}
+
+// PersistentActorSuite runs a PersistentActor against a journal and
+// snapshot store, checking it recovers its state from them. newStores must
+// return empty stores.
+func PersistentActorSuite(t *testing.T, newStores func(t *testing.T) (persistence.Journal, persistence.SnapshotStore)) {
+
+	t.Run("TestRecover", func(t *testing.T) {
+		// Arrange
+		journal, snapshots := newStores(t)
+		first := newCounter(journal, snapshots)
+		first.Start()
+		for _, by := range []int{10, 20, 30} {
+			ask(t, first, by)
+		}
+		first.Stop()
+
+		// Act
+		second := newCounter(journal, snapshots)
+		second.Start()
+		defer second.Stop()
+
+		// Assert
+		if total := ask(t, second, "total"); total != 60 {
+			t.Errorf("expected total 60, got %v", total)
+		}
+		if second.LastSequenceNr() != 3 {
+			t.Errorf("expected sequence number 3, got %d", second.LastSequenceNr())
+		}
+		if latest, ok, _ := snapshots.Load(context.Background(), "counter"); !ok || latest.SequenceNr != 2 {
+			t.Errorf("expected a snapshot at sequence number 2, got %+v", latest)
+		}
+		replayed := ReplayAll(t, journal, "counter", 1, math.MaxUint64)
+		if len(replayed) != 3 || len(replayed[0].Tags) != 0 || !reflect.DeepEqual(replayed[1].Tags, []string{"large"}) {
+			t.Errorf("expected Tagged events to be stored with their tags, got %+v", replayed)
+		}
+	})
+}
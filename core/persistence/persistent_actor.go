@@ -0,0 +1,208 @@
+package persistence
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/EndlessUpHill/goakka/core"
+)
+
+// PersistentActor is an event sourced actor. Its ReceiveCommand handles
+// messages and calls Persist with the events they cause; Persist writes them
+// to the journal and only then passes them to ApplyEvent, the one place the
+// state changes. On start the state is recovered by restoring the latest
+// snapshot, if any, and applying the events persisted after it.
+//
+// Recovery runs when the actor starts under a supervisor or ActorSystem, and
+// otherwise before its first message. An actor restarted from props recovers
+// from scratch, while one restarted in place keeps its state and only applies
+// the events it is missing.
+type PersistentActor struct {
+	*core.BasicActor
+	// ReceiveCommand handles the messages sent to the actor
+	ReceiveCommand func(result *core.ActorResult) *core.ActorResult
+	// ApplyEvent updates the state with a persisted or replayed event
+	ApplyEvent func(event interface{})
+	// SnapshotState returns the state to snapshot, it must not be modified
+	// afterwards. Snapshots are disabled without it.
+	SnapshotState func() interface{}
+	// RestoreSnapshot replaces the state with a snapshotted one
+	RestoreSnapshot func(state interface{})
+
+	persistenceID      string
+	journal            Journal
+	snapshots          SnapshotStore
+	snapshotInterval   uint64
+	sequenceNr         uint64
+	snapshotSequenceNr uint64
+	recovered          bool
+}
+
+// NewPersistentActor creates an actor persisting its events under
+// persistenceID, which must be unique and stable across restarts
+func NewPersistentActor(persistenceID string, journal Journal) *PersistentActor {
+	p := &PersistentActor{
+		BasicActor:    core.NewBasicActor(persistenceID),
+		persistenceID: persistenceID,
+		journal:       journal,
+	}
+	p.BasicActor.ReceiveFunc = p.receive
+	return p
+}
+
+// SetSnapshotStore sets where snapshots are saved and recovered from
+func (p *PersistentActor) SetSnapshotStore(snapshots SnapshotStore) {
+	p.snapshots = snapshots
+}
+
+// SetSnapshotInterval saves a snapshot every interval events, 0 disables
+// automatic snapshots
+func (p *PersistentActor) SetSnapshotInterval(interval uint64) {
+	p.snapshotInterval = interval
+}
+
+// PersistenceID returns the ID the actor's events are stored under
+func (p *PersistentActor) PersistenceID() string {
+	return p.persistenceID
+}
+
+// LastSequenceNr returns the sequence number of the last event applied
+func (p *PersistentActor) LastSequenceNr() uint64 {
+	return p.sequenceNr
+}
+
+// PreStart recovers the actor's state
+func (p *PersistentActor) PreStart() error {
+	return p.recover()
+}
+
+func (p *PersistentActor) receive(result *core.ActorResult) *core.ActorResult {
+	if !p.recovered {
+		if err := p.recover(); err != nil {
+			return &core.ActorResult{Error: err, Action: core.ACTOR_RESTART}
+		}
+	}
+	if p.ReceiveCommand == nil {
+		return &core.ActorResult{
+			Error: fmt.Errorf("no command handler defined for persistent actor %s", p.persistenceID),
+		}
+	}
+	return p.ReceiveCommand(result)
+}
+
+// recover restores the latest snapshot on the first recovery, then applies
+// the events persisted since the last one applied
+func (p *PersistentActor) recover() error {
+	ctx := p.context()
+	if p.sequenceNr == 0 && p.snapshots != nil && p.RestoreSnapshot != nil {
+		snapshot, ok, err := p.snapshots.Load(ctx, p.persistenceID)
+		if err != nil {
+			return fmt.Errorf("could not load snapshot of %s: %w", p.persistenceID, err)
+		}
+		if ok {
+			p.RestoreSnapshot(snapshot.State)
+			p.sequenceNr = snapshot.SequenceNr
+			p.snapshotSequenceNr = snapshot.SequenceNr
+		}
+	}
+
+	err := p.journal.Replay(ctx, p.persistenceID, p.sequenceNr+1, math.MaxUint64, func(event Event) error {
+		p.apply(event.Payload)
+		p.sequenceNr = event.SequenceNr
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("could not replay events of %s: %w", p.persistenceID, err)
+	}
+	// Deleted events are not replayed but still count
+	highest, err := p.journal.HighestSequenceNr(ctx, p.persistenceID)
+	if err != nil {
+		return fmt.Errorf("could not read sequence number of %s: %w", p.persistenceID, err)
+	}
+	if highest > p.sequenceNr {
+		p.sequenceNr = highest
+	}
+	fmt.Printf("Persistent actor %s recovered at sequence number %d\n", p.persistenceID, p.sequenceNr)
+	p.recovered = true
+	return nil
+}
+
+// Persist writes events to the journal atomically and applies them. Events
+// wrapped in Tagged are stored with their tags. Nothing is applied if the
+// write fails, in which case the error should be returned as the result's
+// Error so the supervisor restarts the actor and its state is recovered from
+// the journal.
+func (p *PersistentActor) Persist(events ...interface{}) error {
+	if len(events) == 0 {
+		return nil
+	}
+	now := time.Now()
+	persisted := make([]Event, len(events))
+	for i, event := range events {
+		persisted[i] = Event{
+			PersistenceID: p.persistenceID,
+			SequenceNr:    p.sequenceNr + uint64(i) + 1,
+			Payload:       event,
+			Timestamp:     now,
+		}
+		if tagged, ok := event.(Tagged); ok {
+			persisted[i].Payload = tagged.Payload
+			persisted[i].Tags = tagged.Tags
+		}
+	}
+	if err := p.journal.Write(p.context(), persisted); err != nil {
+		return fmt.Errorf("could not persist events of %s: %w", p.persistenceID, err)
+	}
+
+	for _, event := range persisted {
+		p.apply(event.Payload)
+	}
+	p.sequenceNr += uint64(len(events))
+	if p.snapshotInterval > 0 && p.sequenceNr-p.snapshotSequenceNr >= p.snapshotInterval {
+		if err := p.SaveSnapshot(); err != nil {
+			fmt.Printf("Persistent actor %s could not save snapshot: %v\n", p.persistenceID, err)
+		}
+	}
+	return nil
+}
+
+// context returns the actor's context, for stores used before it started
+func (p *PersistentActor) context() context.Context {
+	if ctx := p.GetContext(); ctx != nil {
+		return ctx
+	}
+	return context.Background()
+}
+
+func (p *PersistentActor) apply(event interface{}) {
+	if p.ApplyEvent != nil {
+		p.ApplyEvent(event)
+	}
+}
+
+// SaveSnapshot stores the current state, so recovery only replays the
+// events persisted after now
+func (p *PersistentActor) SaveSnapshot() error {
+	if p.snapshots == nil || p.SnapshotState == nil {
+		return ErrNoSnapshotStore
+	}
+	err := p.snapshots.Save(p.context(), Snapshot{
+		PersistenceID: p.persistenceID,
+		SequenceNr:    p.sequenceNr,
+		State:         p.SnapshotState(),
+		Timestamp:     time.Now(),
+	})
+	if err != nil {
+		return err
+	}
+	p.snapshotSequenceNr = p.sequenceNr
+	return nil
+}
+
+// DeleteEvents removes the journaled events up to toSequenceNr, typically
+// the sequence number of the latest snapshot
+func (p *PersistentActor) DeleteEvents(toSequenceNr uint64) error {
+	return p.journal.DeleteTo(p.context(), p.persistenceID, toSequenceNr)
+}
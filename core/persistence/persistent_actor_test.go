@@ -0,0 +1,248 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/EndlessUpHill/goakka/core"
+)
+
+// counter is an event sourced counter replying with its total
+type counter struct {
+	*PersistentActor
+	total   int
+	applied int
+}
+
+func newCounter(journal Journal, snapshots SnapshotStore) *counter {
+	c := &counter{PersistentActor: NewPersistentActor("counter", journal)}
+	c.SetSnapshotStore(snapshots)
+	c.ApplyEvent = func(event interface{}) {
+		c.total += event.(incremented).By
+		c.applied++
+	}
+	c.SnapshotState = func() interface{} { return c.total }
+	c.RestoreSnapshot = func(state interface{}) { c.total = state.(int) }
+	c.ReceiveCommand = func(result *core.ActorResult) *core.ActorResult {
+		switch msg := result.Message.(type) {
+		case int:
+			if err := c.Persist(incremented{By: msg}); err != nil {
+				return &core.ActorResult{Error: err}
+			}
+		case string:
+			if msg == "fail" {
+				return &core.ActorResult{Error: errors.New("boom"), Action: core.ACTOR_RESTART}
+			}
+		}
+		result.Reply(c.total)
+		return &core.ActorResult{}
+	}
+	return c
+}
+
+func ask(t *testing.T, actor core.Actor, msg interface{}) (interface{}, error) {
+	t.Helper()
+	future, err := core.Ask(context.Background(), actor, msg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	return future.Await(ctx)
+}
+
+func expectTotal(t *testing.T, actor core.Actor, msg interface{}, expected int) {
+	t.Helper()
+	total, err := ask(t, actor, msg)
+	if err != nil || total != expected {
+		t.Errorf("expected total %d, got %v (%v)", expected, total, err)
+	}
+}
+
+// failingJournal rejects writes while failing is set
+type failingJournal struct {
+	*InMemoryJournal
+	failing bool
+}
+
+func (j *failingJournal) Write(ctx context.Context, events []Event) error {
+	if j.failing {
+		return errors.New("disk full")
+	}
+	return j.InMemoryJournal.Write(ctx, events)
+}
+
+// Test suite for PersistentActor
+func TestPersistentActor(t *testing.T) {
+
+	t.Run("TestRecoverOnStart", func(t *testing.T) {
+		// Arrange
+		journal := NewInMemoryJournal()
+		first := newCounter(journal, nil)
+		first.Start()
+		expectTotal(t, first, 2, 2)
+		expectTotal(t, first, 3, 5)
+		first.Stop()
+
+		// Act
+		second := newCounter(journal, nil)
+		second.Start()
+		defer second.Stop()
+
+		// Assert
+		expectTotal(t, second, 4, 9)
+		if second.LastSequenceNr() != 3 {
+			t.Errorf("expected sequence number 3, got %d", second.LastSequenceNr())
+		}
+	})
+
+	t.Run("TestRecoverOnRestart", func(t *testing.T) {
+		// Arrange
+		journal := NewInMemoryJournal()
+		supervisor := core.NewSupervisor(context.Background())
+		defer supervisor.Stop()
+		var incarnations []*counter
+		actor := supervisor.SuperviseProps(core.PropsFromProducer(func() core.Actor {
+			c := newCounter(journal, nil)
+			incarnations = append(incarnations, c)
+			return c
+		}), "counter")
+		expectTotal(t, actor, 5, 5)
+
+		// Act
+		actor.SendMessage("fail")
+
+		// Assert
+		expectTotal(t, actor, 1, 6)
+		if len(incarnations) != 2 || incarnations[1].applied != 2 {
+			t.Errorf("expected a fresh incarnation to replay the journal")
+		}
+	})
+
+	t.Run("TestSnapshots", func(t *testing.T) {
+		// Arrange
+		journal, snapshots := NewInMemoryJournal(), NewInMemorySnapshotStore()
+		first := newCounter(journal, snapshots)
+		first.SetSnapshotInterval(3)
+		first.Start()
+		for i := 1; i <= 7; i++ {
+			expectTotal(t, first, 1, i)
+		}
+		first.Stop()
+
+		// Act
+		second := newCounter(journal, snapshots)
+		second.Start()
+		defer second.Stop()
+
+		// Assert
+		expectTotal(t, second, "total", 7)
+		if latest, _, _ := snapshots.Load(context.Background(), "counter"); latest.SequenceNr != 6 || latest.State != 6 {
+			t.Errorf("expected a snapshot at sequence number 6, got %+v", latest)
+		}
+		if second.applied != 1 {
+			t.Errorf("expected only the event after the snapshot to be replayed, got %d", second.applied)
+		}
+	})
+
+	t.Run("TestRecoverAfterDeletingEvents", func(t *testing.T) {
+		// Arrange
+		journal, snapshots := NewInMemoryJournal(), NewInMemorySnapshotStore()
+		first := newCounter(journal, snapshots)
+		first.Start()
+		expectTotal(t, first, 10, 10)
+		expectTotal(t, first, 5, 15)
+		first.Stop()
+		first.SaveSnapshot()
+		first.DeleteEvents(first.LastSequenceNr())
+
+		// Act
+		second := newCounter(journal, snapshots)
+		second.Start()
+		defer second.Stop()
+
+		// Assert
+		expectTotal(t, second, 1, 16)
+		if second.LastSequenceNr() != 3 {
+			t.Errorf("expected sequence numbers to continue after the deleted events, got %d", second.LastSequenceNr())
+		}
+	})
+
+	t.Run("TestPersistFailure", func(t *testing.T) {
+		// Arrange
+		journal := &failingJournal{InMemoryJournal: NewInMemoryJournal()}
+		actor := newCounter(journal, nil)
+		actor.Start()
+		defer actor.Stop()
+		expectTotal(t, actor, 1, 1)
+		journal.failing = true
+
+		// Act
+		_, err := ask(t, actor, 1)
+
+		// Assert
+		if err == nil {
+			t.Errorf("expected the failed write to fail the ask")
+		}
+		journal.failing = false
+		expectTotal(t, actor, 1, 2)
+		if actor.applied != 2 {
+			t.Errorf("expected the failed event not to be applied, got %d events", actor.applied)
+		}
+	})
+
+	t.Run("TestTaggedEvents", func(t *testing.T) {
+		// Arrange
+		journal := newFileJournal(t, t.TempDir())
+		actor := newCounter(journal, nil)
+		actor.ReceiveCommand = func(result *core.ActorResult) *core.ActorResult {
+			if err := actor.Persist(Tagged{Payload: incremented{By: result.Message.(int)}, Tags: []string{"counters"}}); err != nil {
+				return &core.ActorResult{Error: err}
+			}
+			result.Reply(actor.total)
+			return &core.ActorResult{}
+		}
+		actor.Start()
+		defer actor.Stop()
+
+		// Act
+		expectTotal(t, actor, 3, 3)
+
+		// Assert
+		var stored []Event
+		journal.Replay(context.Background(), "counter", 1, 1, func(event Event) error {
+			stored = append(stored, event)
+			return nil
+		})
+		if len(stored) != 1 || stored[0].Payload != (incremented{By: 3}) || len(stored[0].Tags) != 1 || stored[0].Tags[0] != "counters" {
+			t.Errorf("expected the event to be stored with its tags, got %+v", stored)
+		}
+	})
+
+	t.Run("TestFileStores", func(t *testing.T) {
+		// Arrange
+		dir := t.TempDir()
+		journal := newFileJournal(t, dir+"/journal")
+		snapshots := newFileSnapshotStore(t, dir+"/snapshots")
+		first := newCounter(journal, snapshots)
+		first.SetSnapshotInterval(2)
+		first.Start()
+		expectTotal(t, first, 4, 4)
+		expectTotal(t, first, 4, 8)
+		expectTotal(t, first, 4, 12)
+		first.Stop()
+
+		// Act
+		second := newCounter(newFileJournal(t, dir+"/journal"), newFileSnapshotStore(t, dir+"/snapshots"))
+		second.Start()
+		defer second.Stop()
+
+		// Assert
+		expectTotal(t, second, "total", 12)
+		if second.applied != 1 {
+			t.Errorf("expected recovery from the snapshot plus one event, got %d events", second.applied)
+		}
+	})
+}
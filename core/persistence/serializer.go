@@ -0,0 +1,39 @@
+package persistence
+
+import (
+	"bytes"
+	"encoding/gob"
+)
+
+// Serializer turns event payloads and snapshot states into bytes for the
+// stores that write them to disk or the network
+type Serializer interface {
+	Marshal(v interface{}) ([]byte, error)
+	Unmarshal(data []byte) (interface{}, error)
+}
+
+// GobSerializer encodes values with encoding/gob, keeping their concrete
+// type. Types other than Go's basic types must be registered with
+// gob.Register before being persisted or replayed.
+type GobSerializer struct{}
+
+// gobValue wraps values so gob records their concrete type
+type gobValue struct {
+	Value interface{}
+}
+
+func (GobSerializer) Marshal(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(gobValue{Value: v}); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (GobSerializer) Unmarshal(data []byte) (interface{}, error) {
+	var value gobValue
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&value); err != nil {
+		return nil, err
+	}
+	return value.Value, nil
+}
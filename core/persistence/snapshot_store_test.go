@@ -0,0 +1,63 @@
+package persistence
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+)
+
+func newFileSnapshotStore(t *testing.T, dir string) SnapshotStore {
+	t.Helper()
+	store, err := NewFileSnapshotStore(dir, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return store
+}
+
+func snapshot(persistenceID string, sequenceNr uint64, state interface{}) Snapshot {
+	return Snapshot{PersistenceID: persistenceID, SequenceNr: sequenceNr, State: state, Timestamp: time.Now()}
+}
+
+// Test suite for the file snapshot store, the shared snapshot store suite
+// runs in conformance_test.go
+func TestFileSnapshotStore(t *testing.T) {
+
+	t.Run("TestFileSnapshotStoreReopen", func(t *testing.T) {
+		// Arrange
+		dir := t.TempDir()
+		newFileSnapshotStore(t, dir).Save(context.Background(), snapshot("orders/42", 7, map[string]int{"total": 7}))
+
+		// Act
+		latest, ok, err := newFileSnapshotStore(t, dir).Load(context.Background(), "orders/42")
+
+		// Assert
+		if err != nil || !ok || latest.SequenceNr != 7 {
+			t.Fatalf("expected the snapshot to survive reopening, got %+v (%v)", latest, err)
+		}
+		if state, _ := latest.State.(map[string]int); state["total"] != 7 {
+			t.Errorf("expected the state to be restored, got %v", latest.State)
+		}
+	})
+
+	t.Run("TestFileSnapshotStoreDotIDs", func(t *testing.T) {
+		// Arrange
+		parent := t.TempDir()
+		store := newFileSnapshotStore(t, parent+"/snapshots")
+
+		// Act
+		store.Save(context.Background(), snapshot("..", 1, 1))
+		store.Save(context.Background(), snapshot(".", 2, 2))
+		up, _, _ := store.Load(context.Background(), "..")
+		here, _, _ := store.Load(context.Background(), ".")
+
+		// Assert
+		if entries, _ := os.ReadDir(parent); len(entries) != 1 {
+			t.Errorf("expected snapshots to stay inside the store, got %d entries next to it", len(entries))
+		}
+		if up.State != 1 || here.State != 2 {
+			t.Errorf("expected the snapshots of . and .. to be kept apart, got %v and %v", up.State, here.State)
+		}
+	})
+}
@@ -0,0 +1,138 @@
+package core
+
+import (
+	"container/heap"
+	"sync"
+)
+
+// Prioritized is implemented by messages that carry their own priority.
+// Higher values are processed first, other messages have priority 0.
+type Prioritized interface {
+	Priority() int
+}
+
+// SystemMessage is implemented by messages that BasicActor delivers through
+// its system lane, ahead of everything waiting in the regular mailbox
+type SystemMessage interface {
+	SystemMessage()
+}
+
+// PriorityMailbox is an unbounded mailbox that hands out the most urgent
+// message first. Messages of equal priority keep their arrival order.
+type PriorityMailbox struct {
+	mu       sync.Mutex
+	messages priorityQueue
+	sequence uint64
+	ready    chan struct{}
+}
+
+// NewPriorityMailbox creates a priority mailbox ordered by less, which
+// reports whether a must be processed before b. With a nil comparator
+// messages are ordered by their Prioritized priority.
+func NewPriorityMailbox(less func(a, b interface{}) bool) *PriorityMailbox {
+	if less == nil {
+		less = func(a, b interface{}) bool {
+			return priorityOf(a) > priorityOf(b)
+		}
+	}
+	return &PriorityMailbox{
+		messages: priorityQueue{less: less},
+		ready:    make(chan struct{}, 1),
+	}
+}
+
+func priorityOf(msg interface{}) int {
+	if p, ok := msg.(Prioritized); ok {
+		return p.Priority()
+	}
+	return 0
+}
+
+func (m *PriorityMailbox) Enqueue(msg interface{}) error {
+	m.mu.Lock()
+	m.sequence++
+	heap.Push(&m.messages, prioritizedMessage{msg: msg, sequence: m.sequence})
+	m.mu.Unlock()
+
+	select {
+	case m.ready <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+func (m *PriorityMailbox) Dequeue() (interface{}, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.messages.Len() == 0 {
+		return nil, false
+	}
+	return heap.Pop(&m.messages).(prioritizedMessage).msg, true
+}
+
+func (m *PriorityMailbox) Ready() <-chan struct{} {
+	return m.ready
+}
+
+func (m *PriorityMailbox) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.messages.Len()
+}
+
+type prioritizedMessage struct {
+	msg      interface{}
+	sequence uint64
+}
+
+// priorityQueue implements heap.Interface
+type priorityQueue struct {
+	items []prioritizedMessage
+	less  func(a, b interface{}) bool
+}
+
+func (q priorityQueue) Len() int {
+	return len(q.items)
+}
+
+func (q priorityQueue) Less(i, j int) bool {
+	a, b := q.items[i], q.items[j]
+	if q.less(unwrapMessage(a.msg), unwrapMessage(b.msg)) {
+		return true
+	}
+	if q.less(unwrapMessage(b.msg), unwrapMessage(a.msg)) {
+		return false
+	}
+	return a.sequence < b.sequence
+}
+
+func (q priorityQueue) Swap(i, j int) {
+	q.items[i], q.items[j] = q.items[j], q.items[i]
+}
+
+func (q *priorityQueue) Push(x interface{}) {
+	q.items = append(q.items, x.(prioritizedMessage))
+}
+
+func (q *priorityQueue) Pop() interface{} {
+	last := len(q.items) - 1
+	item := q.items[last]
+	q.items[last] = prioritizedMessage{}
+	q.items = q.items[:last]
+	return item
+}
+
+// unwrapMessage strips the envelope added by Ask and Tell so comparators
+// only see user messages
+func unwrapMessage(msg interface{}) interface{} {
+	if env, ok := msg.(*envelope); ok {
+		return env.message
+	}
+	return msg
+}
+
+// isSystemMessage reports whether msg belongs in the system lane
+func isSystemMessage(msg interface{}) bool {
+	_, ok := unwrapMessage(msg).(SystemMessage)
+	return ok
+}
@@ -0,0 +1,199 @@
+package core
+
+import (
+	"testing"
+	"time"
+)
+
+type urgent struct {
+	name     string
+	priority int
+}
+
+func (m urgent) Priority() int {
+	return m.priority
+}
+
+type controlMessage string
+
+func (controlMessage) SystemMessage() {}
+
+// Test suite for the priority mailbox and the system lane
+func TestPriorityMailbox(t *testing.T) {
+
+	t.Run("TestPrioritizedMessagesFirst", func(t *testing.T) {
+		// Arrange
+		mailbox := NewPriorityMailbox(nil)
+
+		// Act
+		mailbox.Enqueue("plain")
+		mailbox.Enqueue(urgent{"low", -1})
+		mailbox.Enqueue(urgent{"high", 10})
+		mailbox.Enqueue(urgent{"medium", 5})
+
+		// Assert
+		messages := dequeueAll(mailbox)
+		expected := []interface{}{urgent{"high", 10}, urgent{"medium", 5}, "plain", urgent{"low", -1}}
+		if len(messages) != len(expected) {
+			t.Fatalf("expected %v, got %v", expected, messages)
+		}
+		for i := range expected {
+			if messages[i] != expected[i] {
+				t.Errorf("expected %v at %d, got %v", expected[i], i, messages[i])
+			}
+		}
+	})
+
+	t.Run("TestEqualPrioritiesKeepOrder", func(t *testing.T) {
+		// Arrange
+		mailbox := NewPriorityMailbox(nil)
+
+		// Act
+		for i := 0; i < 100; i++ {
+			mailbox.Enqueue(urgent{priority: i % 2, name: string(rune('a' + i%26))})
+		}
+
+		// Assert
+		messages := dequeueAll(mailbox)
+		for i := 0; i < 50; i++ {
+			if messages[i].(urgent).priority != 1 || messages[i].(urgent).name != string(rune('a'+(2*i+1)%26)) {
+				t.Fatalf("expected arrival order among equal priorities, got %v at %d", messages[i], i)
+			}
+		}
+	})
+
+	t.Run("TestComparator", func(t *testing.T) {
+		// Arrange
+		mailbox := NewPriorityMailbox(func(a, b interface{}) bool {
+			return a.(int) < b.(int)
+		})
+
+		// Act
+		mailbox.Enqueue(3)
+		mailbox.Enqueue(1)
+		mailbox.Enqueue(2)
+
+		// Assert
+		messages := dequeueAll(mailbox)
+		if len(messages) != 3 || messages[0] != 1 || messages[1] != 2 || messages[2] != 3 {
+			t.Errorf("expected [1 2 3], got %v", messages)
+		}
+	})
+
+	t.Run("TestComparatorSeesUnwrappedMessages", func(t *testing.T) {
+		// Arrange
+		received := make(chan interface{}, 3)
+		release := make(chan struct{})
+		actor := NewBasicActorWithMailbox("priority-actor", NewPriorityMailbox(nil))
+		actor.ReceiveFunc = func(result *ActorResult) *ActorResult {
+			if result.Message == "block" {
+				<-release
+				return &ActorResult{}
+			}
+			received <- result.Message
+			return &ActorResult{}
+		}
+		actor.Start()
+		defer actor.Stop()
+		actor.SendMessage("block")
+		time.Sleep(20 * time.Millisecond)
+
+		// Act
+		Tell(actor, "plain", nil)
+		Tell(actor, urgent{"high", 1}, nil)
+		close(release)
+
+		// Assert
+		for _, want := range []interface{}{urgent{"high", 1}, "plain"} {
+			select {
+			case got := <-received:
+				if got != want {
+					t.Errorf("expected %v, got %v", want, got)
+				}
+			case <-time.After(time.Second):
+				t.Fatalf("expected %v to be processed", want)
+			}
+		}
+	})
+
+	t.Run("TestSystemMessagesSkipTheQueue", func(t *testing.T) {
+		// Arrange
+		received := make(chan interface{}, 10)
+		release := make(chan struct{})
+		actor := NewBasicActorWithMailboxSize("busy-actor", 2)
+		actor.ReceiveFunc = func(result *ActorResult) *ActorResult {
+			if result.Message == "block" {
+				<-release
+				return &ActorResult{}
+			}
+			received <- result.Message
+			return &ActorResult{}
+		}
+		actor.Start()
+		defer actor.Stop()
+		actor.SendMessage("block")
+		time.Sleep(20 * time.Millisecond)
+		actor.SendMessage("first")
+		actor.SendMessage("second")
+
+		// Act
+		err := actor.tell(controlMessage("control"))
+		close(release)
+
+		// Assert
+		if err != nil {
+			t.Fatalf("expected the system lane to accept the message despite a full mailbox, got %v", err)
+		}
+		for _, want := range []interface{}{controlMessage("control"), "first", "second"} {
+			select {
+			case got := <-received:
+				if got != want {
+					t.Errorf("expected %v, got %v", want, got)
+				}
+			case <-time.After(time.Second):
+				t.Fatalf("expected %v to be processed", want)
+			}
+		}
+	})
+
+	t.Run("TestTerminatedIsDeliveredFirst", func(t *testing.T) {
+		// Arrange
+		received := make(chan interface{}, 10)
+		release := make(chan struct{})
+		watcher := NewBasicActor("watcher")
+		watcher.ReceiveFunc = func(result *ActorResult) *ActorResult {
+			if result.Message == "block" {
+				<-release
+				return &ActorResult{}
+			}
+			received <- result.Message
+			return &ActorResult{}
+		}
+		watched := NewBasicActor("watched")
+		watched.ReceiveFunc = func(result *ActorResult) *ActorResult {
+			return &ActorResult{}
+		}
+		watcher.Start()
+		defer watcher.Stop()
+		watched.Start()
+		Watch(watcher, watched)
+		watcher.SendMessage("block")
+		time.Sleep(20 * time.Millisecond)
+		watcher.SendMessage("queued")
+
+		// Act
+		watched.Stop()
+		watched.awaitTermination()
+		close(release)
+
+		// Assert
+		select {
+		case got := <-received:
+			if _, ok := got.(Terminated); !ok {
+				t.Errorf("expected Terminated before queued messages, got %v", got)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("expected Terminated to be delivered")
+		}
+	})
+}
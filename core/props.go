@@ -0,0 +1,49 @@
+package core
+
+// Props describes how to create an actor, so the ActorSystem and supervisors
+// can build a fresh instance whenever the actor is restarted
+type Props struct {
+	producer       func() Actor
+	discardMailbox bool
+}
+
+// PropsFromProducer creates Props from a function returning a new actor
+func PropsFromProducer(producer func() Actor) *Props {
+	return &Props{
+		producer: producer,
+	}
+}
+
+// PropsFromFunc creates Props for a BasicActor running receive
+func PropsFromFunc(receive func(result *ActorResult) *ActorResult) *Props {
+	return PropsFromProducer(func() Actor {
+		actor := NewBasicActor("")
+		actor.ReceiveFunc = receive
+		return actor
+	})
+}
+
+// WithMailboxCarryOver returns a copy of the props controlling whether
+// messages still queued when the actor restarts are delivered to the new
+// incarnation (the default) or discarded
+func (p *Props) WithMailboxCarryOver(carryOver bool) *Props {
+	props := *p
+	props.discardMailbox = !carryOver
+	return &props
+}
+
+// newActor builds an actor from the props
+func (p *Props) newActor() Actor {
+	return p.producer()
+}
+
+// reincarnate builds the next incarnation of prev, which must already be stopped
+func (p *Props) reincarnate(prev Actor) Actor {
+	actor := p.newActor()
+	current, previous := asBasicActor(actor), asBasicActor(prev)
+	if current == nil || previous == nil {
+		return actor
+	}
+	current.adopt(previous)
+	return actor
+}
@@ -0,0 +1,159 @@
+package core
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ReceiveTimeout is delivered to an actor that received no message for the
+// duration set with SetReceiveTimeout
+type ReceiveTimeout struct{}
+
+// SetReceiveTimeout makes the actor receive a ReceiveTimeout message after
+// timeout without messages, repeatedly while it stays idle. 0 disables it.
+func (a *BasicActor) SetReceiveTimeout(timeout time.Duration) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.receiveTimeout = timeout
+}
+
+// passivation is shared by the incarnations of an actor so a message sent
+// through any reference wakes it up
+type passivation struct {
+	mu         sync.Mutex
+	timeout    time.Duration
+	passivated bool
+	reactivate func()
+}
+
+// SetPassivationTimeout stops the actor after timeout without messages to
+// free its resources, running PostStop. It stays registered and keeps its
+// mailbox and watchers, and the next message sent to it starts it again:
+// supervised actors created from props get a fresh instance, others are
+// restarted in place. 0 disables passivation.
+func (a *BasicActor) SetPassivationTimeout(timeout time.Duration) {
+	p := a.sharedPassivation()
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.timeout = timeout
+}
+
+// IsPassivated reports whether the actor is stopped until its next message
+func (a *BasicActor) IsPassivated() bool {
+	p := a.sharedPassivation()
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.passivated
+}
+
+func (a *BasicActor) sharedPassivation() *passivation {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.passivation == nil {
+		a.passivation = &passivation{}
+	}
+	return a.passivation
+}
+
+// passivate marks the actor passivated unless messages arrived meanwhile. It
+// returns whether the run loop should exit.
+func (a *BasicActor) passivate() bool {
+	p := a.sharedPassivation()
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if a.mailbox.Len() > 0 || a.systemMailbox.Len() > 0 {
+		return false
+	}
+	fmt.Printf("Passivating idle actor %s\n", a.id)
+	p.passivated = true
+	return true
+}
+
+// activate starts a passivated actor again, it is called after enqueueing
+func (a *BasicActor) activate() {
+	p := a.sharedPassivation()
+	p.mu.Lock()
+	if !p.passivated {
+		p.mu.Unlock()
+		return
+	}
+	p.passivated = false
+	reactivate := p.reactivate
+	p.mu.Unlock()
+
+	fmt.Printf("Reactivating actor %s\n", a.id)
+	if reactivate != nil {
+		reactivate()
+		return
+	}
+	a.awaitTermination()
+	a.reincarnate()
+	a.Start()
+}
+
+// stopPassivated terminates a passivated actor for good, reporting whether
+// it was passivated
+func (a *BasicActor) stopPassivated() bool {
+	p := a.sharedPassivation()
+	p.mu.Lock()
+	passivated := p.passivated
+	p.passivated = false
+	p.mu.Unlock()
+	if passivated {
+		a.awaitTermination()
+		a.terminate()
+	}
+	return passivated
+}
+
+// idleTimers fire when the actor has been idle for its receive timeout or
+// passivation timeout
+type idleTimers struct {
+	receive   *time.Timer
+	passivate *time.Timer
+}
+
+// startIdleTimers arms the timers for an actor whose last message was
+// processed at lastActivity
+func (a *BasicActor) startIdleTimers(lastActivity time.Time) idleTimers {
+	var timers idleTimers
+	a.mu.Lock()
+	receiveTimeout := a.receiveTimeout
+	a.mu.Unlock()
+	if receiveTimeout > 0 {
+		timers.receive = time.NewTimer(receiveTimeout)
+	}
+
+	p := a.sharedPassivation()
+	p.mu.Lock()
+	passivationTimeout := p.timeout
+	p.mu.Unlock()
+	if passivationTimeout > 0 {
+		timers.passivate = time.NewTimer(time.Until(lastActivity.Add(passivationTimeout)))
+	}
+	return timers
+}
+
+func (t idleTimers) receiveC() <-chan time.Time {
+	if t.receive == nil {
+		return nil
+	}
+	return t.receive.C
+}
+
+func (t idleTimers) passivateC() <-chan time.Time {
+	if t.passivate == nil {
+		return nil
+	}
+	return t.passivate.C
+}
+
+func (t idleTimers) stop() {
+	if t.receive != nil {
+		t.receive.Stop()
+	}
+	if t.passivate != nil {
+		t.passivate.Stop()
+	}
+}
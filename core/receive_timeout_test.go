@@ -0,0 +1,176 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// entityActor counts its incarnations and replies with the incarnation that
+// handled each message
+type entityActor struct {
+	*BasicActor
+	incarnation int32
+	events      chan string
+}
+
+func (a *entityActor) PreStart() error {
+	a.events <- fmt.Sprintf("start %d", a.incarnation)
+	return nil
+}
+
+func (a *entityActor) PostStop() {
+	a.events <- fmt.Sprintf("stop %d", a.incarnation)
+}
+
+func newEntityProps(events chan string, passivateAfter time.Duration) *Props {
+	var incarnations int32
+	return PropsFromProducer(func() Actor {
+		a := &entityActor{
+			BasicActor:  NewBasicActor("entity"),
+			incarnation: atomic.AddInt32(&incarnations, 1),
+			events:      events,
+		}
+		a.SetPassivationTimeout(passivateAfter)
+		a.ReceiveFunc = func(result *ActorResult) *ActorResult {
+			result.Reply(a.incarnation)
+			return &ActorResult{}
+		}
+		return a
+	})
+}
+
+// Test suite for receive timeouts and passivation
+func TestReceiveTimeout(t *testing.T) {
+
+	t.Run("TestReceiveTimeoutAfterInactivity", func(t *testing.T) {
+		// Arrange
+		received := make(chan interface{}, 10)
+		actor := NewBasicActor("idle")
+		actor.SetReceiveTimeout(40 * time.Millisecond)
+		actor.ReceiveFunc = func(result *ActorResult) *ActorResult {
+			received <- result.Message
+			return &ActorResult{}
+		}
+		actor.Start()
+		defer actor.Stop()
+
+		// Act
+		for i := 0; i < 5; i++ {
+			actor.SendMessage(i)
+			time.Sleep(10 * time.Millisecond)
+		}
+
+		// Assert
+		expectMessages(t, received, 0, 1, 2, 3, 4, ReceiveTimeout{})
+	})
+
+	t.Run("TestDisableReceiveTimeout", func(t *testing.T) {
+		// Arrange
+		timeouts := make(chan interface{}, 10)
+		actor := NewBasicActor("once")
+		actor.SetReceiveTimeout(5 * time.Millisecond)
+		actor.ReceiveFunc = func(result *ActorResult) *ActorResult {
+			if _, ok := result.Message.(ReceiveTimeout); ok {
+				result.Context().SetReceiveTimeout(0)
+				timeouts <- result.Message
+			}
+			return &ActorResult{}
+		}
+
+		// Act
+		actor.Start()
+		defer actor.Stop()
+
+		// Assert
+		expectMessages(t, timeouts, ReceiveTimeout{})
+		expectNoMessage(t, timeouts, 30*time.Millisecond)
+	})
+
+	t.Run("TestPassivationRecreatesActor", func(t *testing.T) {
+		// Arrange
+		events := make(chan string, 10)
+		system := NewActorSystem(context.Background(), "test-system")
+		defer system.Shutdown()
+		entity, _ := system.ActorOf(newEntityProps(events, 20*time.Millisecond), "entities/42")
+		expectEvents(t, events, "start 1")
+		if reply := askWithTimeout(t, entity, "get"); reply != int32(1) {
+			t.Fatalf("expected the first incarnation to reply, got %v", reply)
+		}
+
+		// Act
+		expectEvents(t, events, "stop 1")
+		passivated := asBasicActor(entity).IsPassivated()
+		found, exists := system.Lookup("/user/entities/42")
+		reply := askWithTimeout(t, entity, "get")
+
+		// Assert
+		if !passivated || !exists || found.GetID() != entity.GetID() {
+			t.Errorf("expected the passivated actor to stay registered")
+		}
+		expectEvents(t, events, "start 2")
+		if reply != int32(2) {
+			t.Errorf("expected a fresh incarnation to handle the message, got %v", reply)
+		}
+		if current, _ := system.Lookup("/user/entities/42"); current.(*entityActor).incarnation != 2 {
+			t.Errorf("expected the registry to point at the new incarnation")
+		}
+	})
+
+	t.Run("TestStoppingPassivatedActor", func(t *testing.T) {
+		// Arrange
+		events := make(chan string, 10)
+		system := NewActorSystem(context.Background(), "test-system")
+		defer system.Shutdown()
+		entity, _ := system.ActorOf(newEntityProps(events, 10*time.Millisecond), "entity")
+		watcher, terminated := newWatcher(t)
+		Watch(watcher, entity)
+		expectEvents(t, events, "start 1", "stop 1")
+
+		// Act
+		system.Stop(entity)
+
+		// Assert
+		if msg := expectTerminated(t, terminated); msg.ID != entity.GetID() {
+			t.Errorf("expected Terminated for the entity, got %v", msg.ID)
+		}
+		if _, exists := system.Lookup("/user/entity"); exists {
+			t.Errorf("expected the stopped actor to be unregistered")
+		}
+		entity.SendMessage("too late")
+		select {
+		case event := <-events:
+			t.Errorf("expected the stopped actor to stay stopped, got %q", event)
+		case <-time.After(20 * time.Millisecond):
+		}
+	})
+
+	t.Run("TestUnsupervisedPassivation", func(t *testing.T) {
+		// Arrange
+		received := make(chan interface{}, 10)
+		actor := NewBasicActor("standalone")
+		actor.SetPassivationTimeout(10 * time.Millisecond)
+		actor.ReceiveFunc = func(result *ActorResult) *ActorResult {
+			received <- result.Message
+			return &ActorResult{}
+		}
+		actor.Start()
+		defer actor.Stop()
+		time.Sleep(30 * time.Millisecond)
+
+		// Act
+		passivated := actor.IsPassivated()
+		actor.SendMessage("wake up")
+
+		// Assert
+		if !passivated {
+			t.Errorf("expected the idle actor to be passivated")
+		}
+		expectMessages(t, received, "wake up")
+		if actor.IsPassivated() {
+			t.Errorf("expected the actor to be running again")
+		}
+	})
+}
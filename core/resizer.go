@@ -0,0 +1,184 @@
+package core
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// ResizerOptions configures an elastic pool router. Every Interval the
+// router counts its busy routees: those with at least PressureThreshold
+// messages queued or being processed, or whose average processing time
+// exceeds LatencyThreshold. When all of them are busy it grows by
+// RampupRate of its size, when fewer than BackoffThreshold of them are it
+// shrinks by BackoffRate, always staying between LowerBound and UpperBound.
+// Zero values are replaced by the defaults of DefaultResizerOptions.
+type ResizerOptions struct {
+	LowerBound        int
+	UpperBound        int
+	PressureThreshold int
+	// LatencyThreshold is ignored when 0
+	LatencyThreshold time.Duration
+	RampupRate       float64
+	BackoffThreshold float64
+	BackoffRate      float64
+	Interval         time.Duration
+}
+
+// DefaultResizerOptions returns options for a pool of 1 to 10 routees
+func DefaultResizerOptions() ResizerOptions {
+	return ResizerOptions{
+		LowerBound:        1,
+		UpperBound:        10,
+		PressureThreshold: 1,
+		RampupRate:        0.2,
+		BackoffThreshold:  0.3,
+		BackoffRate:       0.1,
+		Interval:          100 * time.Millisecond,
+	}
+}
+
+// withDefaults fills the unset options
+func (o ResizerOptions) withDefaults() ResizerOptions {
+	defaults := DefaultResizerOptions()
+	if o.LowerBound <= 0 {
+		o.LowerBound = defaults.LowerBound
+	}
+	if o.UpperBound < o.LowerBound {
+		o.UpperBound = o.LowerBound
+	}
+	if o.PressureThreshold <= 0 {
+		o.PressureThreshold = defaults.PressureThreshold
+	}
+	if o.RampupRate <= 0 {
+		o.RampupRate = defaults.RampupRate
+	}
+	if o.BackoffThreshold <= 0 {
+		o.BackoffThreshold = defaults.BackoffThreshold
+	}
+	if o.BackoffRate <= 0 {
+		o.BackoffRate = defaults.BackoffRate
+	}
+	if o.Interval <= 0 {
+		o.Interval = defaults.Interval
+	}
+	return o
+}
+
+// capacityChange returns how many routees to add, or remove when negative,
+// given the pool size and how many routees are busy
+func (o ResizerOptions) capacityChange(size, busy int) int {
+	switch {
+	case size < o.LowerBound:
+		return o.LowerBound - size
+	case size > o.UpperBound:
+		return o.UpperBound - size
+	case busy >= size && size < o.UpperBound:
+		grow := int(math.Ceil(float64(size) * o.RampupRate))
+		return min(max(grow, 1), o.UpperBound-size)
+	case float64(busy) < float64(size)*o.BackoffThreshold && size > o.LowerBound:
+		shrink := int(math.Ceil(float64(size) * o.BackoffRate))
+		return -min(max(shrink, 1), size-o.LowerBound)
+	}
+	return 0
+}
+
+// resize is the timer message making an elastic pool router evaluate its size
+type resize struct{}
+
+func (resize) SystemMessage() {}
+
+// NewElasticPoolRouter creates a pool router starting with
+// options.LowerBound routees built from props and resizing itself according
+// to options. Routees that crash are restarted by the router's child
+// supervisor; routees that stop for good are replaced on the next resize
+// when the pool falls below its lower bound.
+func NewElasticPoolRouter(name string, props *Props, logic RoutingLogic, options ResizerOptions) *Router {
+	options = options.withDefaults()
+	r := NewPoolRouter(name, options.LowerBound, props, logic)
+	r.resizer = &options
+	return r
+}
+
+// resizePool adds or removes routees according to their load
+func (r *Router) resizePool() {
+	routees := r.Routees()
+	busy := 0
+	for _, routee := range routees {
+		if r.isBusy(routee) {
+			busy++
+		}
+	}
+
+	change := r.resizer.capacityChange(len(routees), busy)
+	if change == 0 {
+		return
+	}
+	fmt.Printf("Router %s resizing from %d to %d routees (%d busy)\n", r.id, len(routees), len(routees)+change, busy)
+	for ; change > 0; change-- {
+		if _, err := r.spawnRoutee(); err != nil {
+			fmt.Printf("Router %s could not add a routee: %v\n", r.id, err)
+			return
+		}
+	}
+	// Only idle routees are removed, newest first, so no message is lost
+	for i := len(routees) - 1; i >= 0 && change < 0; i-- {
+		if b := asBasicActor(routees[i]); b != nil && b.pendingMessages() == 0 {
+			r.removeRoutee(b)
+			change++
+		}
+	}
+}
+
+// isBusy reports whether routee is under pressure
+func (r *Router) isBusy(routee Actor) bool {
+	b := asBasicActor(routee)
+	if b == nil {
+		return false
+	}
+	if b.pendingMessages() >= r.resizer.PressureThreshold {
+		return true
+	}
+	return r.resizer.LatencyThreshold > 0 && b.processingLatency() > r.resizer.LatencyThreshold
+}
+
+// removeRoutee stops routing to routee and stops it
+func (r *Router) removeRoutee(routee *BasicActor) {
+	r.removeChild(routee)
+	if supervisor := r.currentSupervisor(); supervisor != nil {
+		supervisor.StopActor(routee.outer())
+		return
+	}
+	routee.Stop()
+}
+
+// latencyWeight is how much the latest processing time moves the average
+const latencyWeight = 0.2
+
+// recordLatency folds the processing time of a message into the moving
+// average, must be called with a.mu held
+func (a *BasicActor) recordLatency(latency time.Duration) {
+	if a.latency == 0 {
+		a.latency = latency
+		return
+	}
+	a.latency += time.Duration(latencyWeight * float64(latency-a.latency))
+}
+
+// processingLatency returns the average time the actor takes per message
+func (a *BasicActor) processingLatency() time.Duration {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.latency
+}
+
+// pendingMessages returns the number of messages queued or being processed
+func (a *BasicActor) pendingMessages() int {
+	pending := a.mailbox.Len()
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.current != nil {
+		pending++
+	}
+	return pending
+}
@@ -0,0 +1,147 @@
+package core
+
+import (
+	"testing"
+	"time"
+)
+
+func expectRouteeCount(t *testing.T, router *Router, expected int) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for len(router.Routees()) != expected {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected %d routees, got %d", expected, len(router.Routees()))
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// Test suite for elastic pool routers
+func TestResizer(t *testing.T) {
+
+	t.Run("TestCapacityChange", func(t *testing.T) {
+		options := ResizerOptions{LowerBound: 2, UpperBound: 10}.withDefaults()
+		cases := []struct {
+			size, busy, expected int
+		}{
+			{size: 1, busy: 0, expected: 1},
+			{size: 5, busy: 5, expected: 1},
+			{size: 8, busy: 8, expected: 2},
+			{size: 9, busy: 9, expected: 1},
+			{size: 10, busy: 10, expected: 0},
+			{size: 5, busy: 3, expected: 0},
+			{size: 5, busy: 1, expected: -1},
+			{size: 2, busy: 0, expected: 0},
+			{size: 12, busy: 12, expected: -2},
+		}
+		for _, c := range cases {
+			if change := options.capacityChange(c.size, c.busy); change != c.expected {
+				t.Errorf("expected %d routees with %d busy to change by %d, got %d", c.size, c.busy, c.expected, change)
+			}
+		}
+	})
+
+	t.Run("TestGrowAndShrink", func(t *testing.T) {
+		// Arrange
+		release := make(chan struct{})
+		processed := make(chan interface{}, 100)
+		props := PropsFromFunc(func(result *ActorResult) *ActorResult {
+			<-release
+			processed <- result.Message
+			return &ActorResult{}
+		})
+		router := startRouter(t, NewElasticPoolRouter("elastic", props, NewSmallestMailboxRouting(), ResizerOptions{
+			LowerBound: 1,
+			UpperBound: 4,
+			RampupRate: 1,
+			Interval:   10 * time.Millisecond,
+		}))
+		expectRouteeCount(t, router, 1)
+
+		// Act
+		sent := 0
+		for deadline := time.Now().Add(2 * time.Second); len(router.Routees()) < 4 && time.Now().Before(deadline); sent++ {
+			router.SendMessage(sent)
+			time.Sleep(2 * time.Millisecond)
+		}
+
+		// Assert
+		expectRouteeCount(t, router, 4)
+		close(release)
+		for i := 0; i < sent; i++ {
+			select {
+			case <-processed:
+			case <-time.After(time.Second):
+				t.Fatalf("expected every message to be processed, got %d", i)
+			}
+		}
+		expectRouteeCount(t, router, 1)
+	})
+
+	t.Run("TestLatencyPressure", func(t *testing.T) {
+		// Arrange
+		router := NewElasticPoolRouter("elastic", PropsFromFunc(nil), NewRoundRobinRouting(), ResizerOptions{
+			PressureThreshold: 100,
+			LatencyThreshold:  time.Millisecond,
+		})
+		slow, fast := NewBasicActor("slow"), NewBasicActor("fast")
+		slow.recordLatency(10 * time.Millisecond)
+		fast.recordLatency(100 * time.Microsecond)
+
+		// Act
+		slowBusy, fastBusy := router.isBusy(slow), router.isBusy(fast)
+
+		// Assert
+		if !slowBusy || fastBusy {
+			t.Errorf("expected only the slow routee to be busy, got slow=%v fast=%v", slowBusy, fastBusy)
+		}
+	})
+
+	t.Run("TestReplacesStoppedRoutees", func(t *testing.T) {
+		// Arrange
+		router := startRouter(t, NewElasticPoolRouter("elastic", namedRouteeProps(), NewRoundRobinRouting(), ResizerOptions{
+			LowerBound: 2,
+			UpperBound: 2,
+			Interval:   10 * time.Millisecond,
+		}))
+		expectRouteeCount(t, router, 2)
+		stopped := router.Routees()[0]
+
+		// Act
+		stopped.Stop()
+
+		// Assert
+		time.Sleep(30 * time.Millisecond)
+		expectRouteeCount(t, router, 2)
+		for _, routee := range router.Routees() {
+			if routee.GetID() == stopped.GetID() {
+				t.Errorf("expected the stopped routee to be replaced")
+			}
+		}
+		if reply := askWithTimeout(t, router, "name"); reply != "routee-2" && reply != "routee-3" {
+			t.Errorf("expected a live routee to reply, got %v", reply)
+		}
+	})
+
+	t.Run("TestRestartsCrashedRoutees", func(t *testing.T) {
+		// Arrange
+		router := startRouter(t, NewElasticPoolRouter("elastic", namedRouteeProps(), NewRoundRobinRouting(), ResizerOptions{
+			LowerBound: 1,
+			UpperBound: 1,
+		}))
+		expectRouteeCount(t, router, 1)
+		crashed := router.Routees()[0]
+
+		// Act
+		router.SendMessage("fail")
+		reply := askWithTimeout(t, router, "name")
+
+		// Assert
+		if reply != "routee-1" || router.Routees()[0].GetID() != crashed.GetID() {
+			t.Errorf("expected the crashed routee to be restarted in place, got %v", reply)
+		}
+		if asBasicActor(router.Routees()[0]) == asBasicActor(crashed) {
+			t.Errorf("expected a fresh routee instance after the crash")
+		}
+	})
+}
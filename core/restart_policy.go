@@ -0,0 +1,61 @@
+package core
+
+import (
+	"math"
+	"math/rand"
+	"time"
+)
+
+// BackoffOptions configures delayed restarts of failing children. The delay
+// doubles with every consecutive restart between MinBackoff and MaxBackoff,
+// stretched by up to RandomFactor of itself to avoid restarting in lockstep.
+type BackoffOptions struct {
+	MinBackoff   time.Duration
+	MaxBackoff   time.Duration
+	RandomFactor float64
+	// ResetAfter is how long a child must run without failing before its
+	// delay starts again from MinBackoff
+	ResetAfter time.Duration
+}
+
+// delay returns the backoff before the given restart attempt, starting at 0.
+// Without MaxBackoff the delay grows until it saturates at the longest
+// time.Duration instead of overflowing.
+func (o BackoffOptions) delay(attempt int) time.Duration {
+	backoff := float64(o.MinBackoff) * math.Pow(2, float64(attempt))
+	if o.MaxBackoff > 0 && backoff > float64(o.MaxBackoff) {
+		backoff = float64(o.MaxBackoff)
+	}
+	if o.RandomFactor > 0 {
+		backoff += backoff * o.RandomFactor * rand.Float64()
+	}
+	// float64(math.MaxInt64) rounds up to 2^63, which does not convert back
+	if backoff >= float64(math.MaxInt64) {
+		return time.Duration(math.MaxInt64)
+	}
+	return time.Duration(backoff)
+}
+
+// restartIntensity limits how many restarts a supervisor performs within a
+// time window before giving up, like OTP's MaxR/MaxT
+type restartIntensity struct {
+	maxRestarts int
+	within      time.Duration
+	restarts    []time.Time
+}
+
+// allow records a restart at now and reports whether the limit still holds
+func (r *restartIntensity) allow(now time.Time) bool {
+	if r.maxRestarts <= 0 {
+		return true
+	}
+
+	recent := r.restarts[:0]
+	for _, at := range r.restarts {
+		if now.Sub(at) < r.within {
+			recent = append(recent, at)
+		}
+	}
+	r.restarts = append(recent, now)
+	return len(r.restarts) <= r.maxRestarts
+}
@@ -0,0 +1,85 @@
+package core
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+// Test suite for restart intensity and backoff
+func TestRestartPolicy(t *testing.T) {
+
+	t.Run("TestBackoffDelayGrowsAndCaps", func(t *testing.T) {
+		// Arrange
+		options := BackoffOptions{
+			MinBackoff: 10 * time.Millisecond,
+			MaxBackoff: 50 * time.Millisecond,
+		}
+
+		// Act & Assert
+		expected := []time.Duration{10, 20, 40, 50, 50}
+		for attempt, want := range expected {
+			if got := options.delay(attempt); got != want*time.Millisecond {
+				t.Errorf("attempt %d: expected %s, got %s", attempt, want*time.Millisecond, got)
+			}
+		}
+	})
+
+	t.Run("TestBackoffJitterStaysInRange", func(t *testing.T) {
+		// Arrange
+		options := BackoffOptions{
+			MinBackoff:   10 * time.Millisecond,
+			MaxBackoff:   time.Second,
+			RandomFactor: 0.5,
+		}
+
+		// Act & Assert
+		for i := 0; i < 100; i++ {
+			delay := options.delay(1)
+			if delay < 20*time.Millisecond || delay > 30*time.Millisecond {
+				t.Fatalf("expected delay between 20ms and 30ms, got %s", delay)
+			}
+		}
+	})
+
+	t.Run("TestBackoffWithoutMaxSaturates", func(t *testing.T) {
+		// Arrange
+		options := BackoffOptions{
+			MinBackoff:   time.Second,
+			RandomFactor: 0.5,
+		}
+
+		// Act & Assert
+		previous := time.Duration(0)
+		for attempt := 0; attempt < 2000; attempt += 7 {
+			delay := options.delay(attempt)
+			if delay < previous && delay != time.Duration(math.MaxInt64) {
+				t.Fatalf("attempt %d: expected the delay to keep growing, got %s after %s", attempt, delay, previous)
+			}
+			if delay < 0 {
+				t.Fatalf("attempt %d: expected a positive delay, got %s", attempt, delay)
+			}
+			previous = delay
+		}
+		if previous != time.Duration(math.MaxInt64) {
+			t.Errorf("expected the delay to saturate, got %s", previous)
+		}
+	})
+
+	t.Run("TestRestartIntensityWindow", func(t *testing.T) {
+		// Arrange
+		intensity := restartIntensity{maxRestarts: 2, within: time.Second}
+		now := time.Now()
+
+		// Act & Assert
+		if !intensity.allow(now) || !intensity.allow(now.Add(100*time.Millisecond)) {
+			t.Errorf("expected restarts within the limit to be allowed")
+		}
+		if intensity.allow(now.Add(200 * time.Millisecond)) {
+			t.Errorf("expected the third restart within the window to be refused")
+		}
+		if !intensity.allow(now.Add(2 * time.Second)) {
+			t.Errorf("expected restarts to be allowed again after the window")
+		}
+	})
+}
@@ -0,0 +1,154 @@
+package core
+
+import (
+	"fmt"
+)
+
+// Broadcast makes a router forward Message to all of its routees, whatever
+// its routing logic
+type Broadcast struct {
+	Message interface{}
+}
+
+// Router is an actor forwarding the messages it receives to routees chosen
+// by a RoutingLogic. Messages sent with Ask or Tell keep their reply target,
+// so routees answer the original sender directly. Messages that cannot be
+// routed go to dead letters.
+//
+// A pool router creates its routees from props when it starts and owns them:
+// they are its children, supervised by its child supervisor (see
+// SetChildSupervision) and stopped with it. An elastic pool router also
+// grows and shrinks with the load, see NewElasticPoolRouter.
+//
+// A group router forwards to actors that already exist in an ActorRegistry,
+// looked up by key for every message. Without a registry of its own it uses
+// the registry of its ActorSystem.
+type Router struct {
+	*BasicActor
+	logic    RoutingLogic
+	props    *Props
+	size     int
+	registry *ActorRegistry
+	paths    []string
+	spawned  int
+	resizer  *ResizerOptions
+}
+
+// NewPoolRouter creates a router owning size routees built from props
+func NewPoolRouter(name string, size int, props *Props, logic RoutingLogic) *Router {
+	r := newRouter(name, logic)
+	r.props = props
+	r.size = size
+	return r
+}
+
+// NewGroupRouter creates a router forwarding to the actors registered in
+// registry under paths, e.g. the names given to RegisterActor or the paths
+// of an ActorSystem such as "/user/workers/1". A nil registry stands for the
+// registry of the router's ActorSystem.
+func NewGroupRouter(name string, registry *ActorRegistry, logic RoutingLogic, paths ...string) *Router {
+	r := newRouter(name, logic)
+	r.registry = registry
+	r.paths = paths
+	return r
+}
+
+func newRouter(name string, logic RoutingLogic) *Router {
+	r := &Router{
+		BasicActor: NewBasicActor(name),
+		logic:      logic,
+	}
+	r.BasicActor.self = r
+	r.BasicActor.ReceiveFunc = r.route
+	return r
+}
+
+// PreStart creates the routees of a pool router
+func (r *Router) PreStart() error {
+	r.spawned = 0
+	for i := 0; i < r.size; i++ {
+		if _, err := r.spawnRoutee(); err != nil {
+			return err
+		}
+	}
+	if r.resizer != nil {
+		r.StartTimer("resize", resize{}, r.resizer.Interval)
+	}
+	return nil
+}
+
+// Routees returns the live routees of the router
+func (r *Router) Routees() []Actor {
+	if r.props != nil {
+		return r.Children()
+	}
+	registry := r.registry
+	if registry == nil && r.system != nil {
+		registry = r.system.registry
+	}
+	if registry == nil {
+		return nil
+	}
+	routees := make([]Actor, 0, len(r.paths))
+	for _, path := range r.paths {
+		if routee, ok := registry.GetActor(path); ok {
+			routees = append(routees, routee)
+		}
+	}
+	return routees
+}
+
+// spawnRoutee creates the next routee of a pool router as its child, in its
+// actor system when it has one
+func (r *Router) spawnRoutee() (Actor, error) {
+	r.spawned++
+	name := fmt.Sprintf("routee-%d", r.spawned)
+	if r.system != nil {
+		return r.spawnChild(r.props, name)
+	}
+
+	routee := r.props.newActor()
+	if b := asBasicActor(routee); b != nil {
+		b.name = name
+		r.addChild(b)
+	}
+	r.childSupervisor().superviseActor(routee, r.props)
+	return routee, nil
+}
+
+func (r *Router) route(result *ActorResult) *ActorResult {
+	msg := result.Message
+	if _, ok := msg.(resize); ok {
+		r.resizePool()
+		return &ActorResult{}
+	}
+	routees := r.Routees()
+	var targets []Actor
+	var err error
+	if broadcast, ok := msg.(Broadcast); ok {
+		msg = broadcast.Message
+		targets = routees
+	} else if len(routees) > 0 {
+		targets, err = r.logic.Select(msg, routees)
+	}
+	if err == nil && len(targets) == 0 {
+		err = ErrNoRoutees
+	}
+
+	forwarded := msg
+	if result.future != nil || result.Sender != nil {
+		forwarded = &envelope{message: msg, sender: result.Sender, future: result.future}
+	}
+	if err != nil {
+		fmt.Printf("Router %s could not route message %v: %v\n", r.id, msg, err)
+		letter := deadLetter(forwarded, r, err)
+		if r.deadLetters != nil {
+			r.deadLetters.Publish(letter)
+		}
+		return &ActorResult{}
+	}
+	for _, target := range targets {
+		target.SendMessage(forwarded)
+	}
+	return &ActorResult{}
+}
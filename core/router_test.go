@@ -0,0 +1,321 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+// keyedMessage is routed by its key with consistent hashing
+type keyedMessage struct {
+	key string
+}
+
+func (m keyedMessage) ConsistentHashKey() string {
+	return m.key
+}
+
+// namedRouteeProps creates routees replying with their own name
+func namedRouteeProps() *Props {
+	return PropsFromProducer(func() Actor {
+		routee := NewBasicActor("")
+		routee.ReceiveFunc = func(result *ActorResult) *ActorResult {
+			if result.Message == "fail" {
+				return &ActorResult{Error: errors.New("boom"), Action: ACTOR_RESTART}
+			}
+			result.Reply(routee.GetName())
+			return &ActorResult{}
+		}
+		return routee
+	})
+}
+
+func startRouter(t *testing.T, router *Router) *Router {
+	t.Helper()
+	router.Start()
+	t.Cleanup(router.Stop)
+	return router
+}
+
+// Test suite for routers and routing logics
+func TestRouter(t *testing.T) {
+
+	t.Run("TestRoundRobinPool", func(t *testing.T) {
+		// Arrange
+		router := startRouter(t, NewPoolRouter("pool", 3, namedRouteeProps(), NewRoundRobinRouting()))
+
+		// Act
+		var replies []interface{}
+		for i := 0; i < 6; i++ {
+			replies = append(replies, askWithTimeout(t, router, "name"))
+		}
+
+		// Assert
+		expected := []interface{}{"routee-1", "routee-2", "routee-3", "routee-1", "routee-2", "routee-3"}
+		if fmt.Sprint(replies) != fmt.Sprint(expected) {
+			t.Errorf("expected replies %v, got %v", expected, replies)
+		}
+	})
+
+	t.Run("TestRandomPool", func(t *testing.T) {
+		// Arrange
+		router := startRouter(t, NewPoolRouter("pool", 3, namedRouteeProps(), NewRandomRouting()))
+
+		// Act
+		used := make(map[interface{}]bool)
+		for i := 0; i < 50; i++ {
+			used[askWithTimeout(t, router, "name")] = true
+		}
+
+		// Assert
+		if len(used) < 2 {
+			t.Errorf("expected messages to be spread across routees, got %v", used)
+		}
+		for name := range used {
+			if name != "routee-1" && name != "routee-2" && name != "routee-3" {
+				t.Errorf("unexpected routee %v", name)
+			}
+		}
+	})
+
+	t.Run("TestBroadcastGroup", func(t *testing.T) {
+		// Arrange
+		system := NewActorSystem(context.Background(), "test-system")
+		defer system.Shutdown()
+		received := make(chan interface{}, 10)
+		for _, name := range []string{"workers/1", "workers/2", "workers/3"} {
+			name := name
+			system.ActorOf(PropsFromFunc(func(result *ActorResult) *ActorResult {
+				received <- name + ":" + fmt.Sprint(result.Message)
+				return &ActorResult{}
+			}), name)
+		}
+		router, _ := system.ActorOf(PropsFromProducer(func() Actor {
+			return NewGroupRouter("workers", nil, NewBroadcastRouting(), "/user/workers/1", "/user/workers/2", "/user/workers/3")
+		}), "broadcaster")
+
+		// Act
+		router.SendMessage("job")
+
+		// Assert
+		got := make(map[interface{}]bool)
+		for i := 0; i < 3; i++ {
+			select {
+			case msg := <-received:
+				got[msg] = true
+			case <-time.After(time.Second):
+				t.Fatalf("expected every routee to receive the message, got %v", got)
+			}
+		}
+		for _, want := range []string{"workers/1:job", "workers/2:job", "workers/3:job"} {
+			if !got[want] {
+				t.Errorf("expected %q to be received", want)
+			}
+		}
+	})
+
+	t.Run("TestGroupSkipsMissingRoutees", func(t *testing.T) {
+		// Arrange
+		system := NewActorSystem(context.Background(), "test-system")
+		defer system.Shutdown()
+		system.ActorOf(namedRouteeProps(), "present")
+		router, _ := system.ActorOf(PropsFromProducer(func() Actor {
+			return NewGroupRouter("group", nil, NewRoundRobinRouting(), "/user/missing", "/user/present")
+		}), "group")
+
+		// Act
+		first := askWithTimeout(t, router, "name")
+		second := askWithTimeout(t, router, "name")
+
+		// Assert
+		if first != "present" || second != "present" {
+			t.Errorf("expected only the registered routee to be used, got %v and %v", first, second)
+		}
+	})
+
+	t.Run("TestGroupOverRegistry", func(t *testing.T) {
+		// Arrange
+		registry := NewActorRegistry()
+		for _, name := range []string{"first", "second"} {
+			routee := NewBasicActor(name)
+			routee.ReceiveFunc = func(result *ActorResult) *ActorResult {
+				result.Reply(routee.GetName())
+				return &ActorResult{}
+			}
+			registry.RegisterActor(routee)
+			routee.Start()
+			defer routee.Stop()
+		}
+		router := startRouter(t, NewGroupRouter("group", registry, NewRoundRobinRouting(), "first", "second"))
+
+		// Act
+		first := askWithTimeout(t, router, "name")
+		second := askWithTimeout(t, router, "name")
+
+		// Assert
+		if first != "first" || second != "second" {
+			t.Errorf("expected the registered actors to take turns, got %v and %v", first, second)
+		}
+	})
+
+	t.Run("TestBroadcastMessage", func(t *testing.T) {
+		// Arrange
+		received := make(chan interface{}, 10)
+		props := PropsFromFunc(func(result *ActorResult) *ActorResult {
+			received <- result.Message
+			return &ActorResult{}
+		})
+		router := startRouter(t, NewPoolRouter("pool", 3, props, NewRoundRobinRouting()))
+
+		// Act
+		router.SendMessage(Broadcast{Message: "shutdown"})
+
+		// Assert
+		expectMessages(t, received, "shutdown", "shutdown", "shutdown")
+		expectNoMessage(t, received, 20*time.Millisecond)
+	})
+
+	t.Run("TestConsistentHashPool", func(t *testing.T) {
+		// Arrange
+		deadLetters, letters := collectDeadLetters(t)
+		router := NewPoolRouter("pool", 4, namedRouteeProps(), NewConsistentHashRouting(nil))
+		router.SetDeadLetters(deadLetters)
+		startRouter(t, router)
+
+		// Act
+		owners := make(map[string]interface{})
+		spread := make(map[interface{}]bool)
+		for i := 0; i < 20; i++ {
+			key := fmt.Sprintf("customer-%d", i)
+			owners[key] = askWithTimeout(t, router, keyedMessage{key: key})
+			spread[owners[key]] = true
+		}
+		router.SendMessage("no key")
+
+		// Assert
+		for key, owner := range owners {
+			if again := askWithTimeout(t, router, keyedMessage{key: key}); again != owner {
+				t.Errorf("expected %s to stay on %v, got %v", key, owner, again)
+			}
+		}
+		if len(spread) < 2 {
+			t.Errorf("expected keys to be spread across routees, got %v", spread)
+		}
+		if letter := expectDeadLetter(t, letters); letter.Message != "no key" || !errors.Is(letter.Reason, ErrNoHashKey) {
+			t.Errorf("expected the unkeyed message to be a dead letter, got %v (%v)", letter.Message, letter.Reason)
+		}
+	})
+
+	t.Run("TestConsistentHashKeyFunc", func(t *testing.T) {
+		// Arrange
+		routees := []Actor{NewBasicActor("a"), NewBasicActor("b"), NewBasicActor("c")}
+		logic := NewConsistentHashRouting(func(msg interface{}) (string, bool) {
+			s, ok := msg.(string)
+			return s, ok
+		})
+
+		// Act
+		first, err := logic.Select("order-7", routees)
+		reordered, _ := logic.Select("order-7", []Actor{routees[2], routees[0], routees[1]})
+		_, errNoKey := logic.Select(42, routees)
+
+		// Assert
+		if err != nil || first[0] != reordered[0] {
+			t.Errorf("expected the key to stay on its routee regardless of order, got %v and %v", first, reordered)
+		}
+		if !errors.Is(errNoKey, ErrNoHashKey) {
+			t.Errorf("expected ErrNoHashKey, got %v", errNoKey)
+		}
+	})
+
+	t.Run("TestSmallestMailbox", func(t *testing.T) {
+		// Arrange
+		release := make(chan struct{})
+		received := make(chan interface{}, 10)
+		busy := NewBasicActor("busy")
+		busy.ReceiveFunc = func(result *ActorResult) *ActorResult {
+			<-release
+			return &ActorResult{}
+		}
+		idle := NewBasicActor("idle")
+		idle.ReceiveFunc = func(result *ActorResult) *ActorResult {
+			received <- result.Message
+			return &ActorResult{}
+		}
+		busy.Start()
+		idle.Start()
+		defer busy.Stop()
+		defer idle.Stop()
+		defer close(release)
+		for i := 0; i < 3; i++ {
+			busy.SendMessage(i)
+		}
+		logic := NewSmallestMailboxRouting()
+
+		// Act
+		selected, err := logic.Select("job", []Actor{busy, idle})
+
+		// Assert
+		if err != nil || selected[0] != idle {
+			t.Errorf("expected the idle routee to be selected, got %v", selected)
+		}
+	})
+
+	t.Run("TestNoRoutees", func(t *testing.T) {
+		// Arrange
+		deadLetters, letters := collectDeadLetters(t)
+		router := NewGroupRouter("empty", nil, NewRoundRobinRouting())
+		router.SetDeadLetters(deadLetters)
+		startRouter(t, router)
+
+		// Act
+		future, _ := Ask(context.Background(), router, "lost")
+		_, err := future.Await(context.Background())
+
+		// Assert
+		if !errors.Is(err, ErrNoRoutees) {
+			t.Errorf("expected the ask to fail with ErrNoRoutees, got %v", err)
+		}
+		if letter := expectDeadLetter(t, letters); letter.Message != "lost" || letter.Recipient.GetID() != router.GetID() {
+			t.Errorf("expected the message to be a dead letter of the router, got %v", letter)
+		}
+	})
+
+	t.Run("TestPoolRouteesSupervised", func(t *testing.T) {
+		// Arrange
+		system := NewActorSystem(context.Background(), "test-system")
+		defer system.Shutdown()
+		actor, _ := system.ActorOf(PropsFromProducer(func() Actor {
+			return NewPoolRouter("pool", 2, namedRouteeProps(), NewRoundRobinRouting())
+		}), "pool")
+		router := actor.(*Router)
+		askWithTimeout(t, router, "name")
+		routees := router.Routees()
+
+		// Act
+		routees[0].SendMessage("fail")
+		time.Sleep(20 * time.Millisecond)
+		replies := map[interface{}]bool{
+			askWithTimeout(t, router, "name"): true,
+			askWithTimeout(t, router, "name"): true,
+		}
+		_, registered := system.Lookup("/user/pool/routee-1")
+		system.Stop(router)
+		router.awaitTermination()
+
+		// Assert
+		if !registered {
+			t.Errorf("expected the routees to be registered children of the router")
+		}
+		if !replies["routee-1"] || !replies["routee-2"] {
+			t.Errorf("expected the restarted routee to keep receiving messages, got %v", replies)
+		}
+		for _, routee := range routees {
+			if _, exists := system.Lookup(routee.(*BasicActor).GetPath()); exists {
+				t.Errorf("expected routee %s to stop with the router", routee.GetName())
+			}
+		}
+	})
+}
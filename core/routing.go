@@ -0,0 +1,191 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"math/rand"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+)
+
+var (
+	// ErrNoRoutees is the dead letter reason for messages sent to a router
+	// without live routees
+	ErrNoRoutees = errors.New("router has no routees")
+	// ErrNoHashKey is the dead letter reason for messages a consistent-hash
+	// router cannot derive a key from
+	ErrNoHashKey = errors.New("message has no consistent hash key")
+)
+
+// RoutingLogic picks the routees a message is forwarded to. routees is never
+// empty and Select may be called from several routers at once.
+type RoutingLogic interface {
+	Select(msg interface{}, routees []Actor) ([]Actor, error)
+}
+
+// RoundRobinRouting sends each message to the next routee in turn
+type RoundRobinRouting struct {
+	next uint64
+}
+
+func NewRoundRobinRouting() *RoundRobinRouting {
+	return &RoundRobinRouting{}
+}
+
+func (r *RoundRobinRouting) Select(msg interface{}, routees []Actor) ([]Actor, error) {
+	i := atomic.AddUint64(&r.next, 1) - 1
+	return []Actor{routees[i%uint64(len(routees))]}, nil
+}
+
+// RandomRouting sends each message to a routee picked at random
+type RandomRouting struct{}
+
+func NewRandomRouting() *RandomRouting {
+	return &RandomRouting{}
+}
+
+func (r *RandomRouting) Select(msg interface{}, routees []Actor) ([]Actor, error) {
+	return []Actor{routees[rand.Intn(len(routees))]}, nil
+}
+
+// BroadcastRouting sends every message to all routees
+type BroadcastRouting struct{}
+
+func NewBroadcastRouting() *BroadcastRouting {
+	return &BroadcastRouting{}
+}
+
+func (r *BroadcastRouting) Select(msg interface{}, routees []Actor) ([]Actor, error) {
+	return routees, nil
+}
+
+// SmallestMailboxRouting sends each message to the routee with the fewest
+// queued messages, the first one on ties. Routees not backed by a BasicActor
+// are only picked when no other routee is available.
+type SmallestMailboxRouting struct{}
+
+func NewSmallestMailboxRouting() *SmallestMailboxRouting {
+	return &SmallestMailboxRouting{}
+}
+
+func (r *SmallestMailboxRouting) Select(msg interface{}, routees []Actor) ([]Actor, error) {
+	selected, smallest := routees[0], -1
+	for _, routee := range routees {
+		b := asBasicActor(routee)
+		if b == nil {
+			continue
+		}
+		if size := b.MailboxSize(); smallest < 0 || size < smallest {
+			selected, smallest = routee, size
+		}
+	}
+	return []Actor{selected}, nil
+}
+
+// ConsistentHashable is implemented by messages that carry the key a
+// consistent-hash router routes them by
+type ConsistentHashable interface {
+	ConsistentHashKey() string
+}
+
+// virtualNodes is the number of points each routee owns on the hash ring,
+// spreading keys evenly across few routees
+const virtualNodes = 100
+
+// ConsistentHashRouting sends messages with the same key to the same routee
+// as long as the routees do not change. Adding or removing a routee only
+// moves the keys of its share of the ring.
+type ConsistentHashRouting struct {
+	hashKey func(msg interface{}) (string, bool)
+	mu      sync.Mutex
+	members string
+	ring    []ringNode
+}
+
+// ringNode is a point on the hash ring owned by routees[index]
+type ringNode struct {
+	hash  uint32
+	index int
+}
+
+// NewConsistentHashRouting creates a consistent-hash logic keying messages
+// with hashKey. With a nil hashKey messages must implement
+// ConsistentHashable. Messages without a key go to dead letters.
+func NewConsistentHashRouting(hashKey func(msg interface{}) (string, bool)) *ConsistentHashRouting {
+	if hashKey == nil {
+		hashKey = func(msg interface{}) (string, bool) {
+			if hashable, ok := msg.(ConsistentHashable); ok {
+				return hashable.ConsistentHashKey(), true
+			}
+			return "", false
+		}
+	}
+	return &ConsistentHashRouting{hashKey: hashKey}
+}
+
+func (r *ConsistentHashRouting) Select(msg interface{}, routees []Actor) ([]Actor, error) {
+	key, ok := r.hashKey(msg)
+	if !ok {
+		return nil, fmt.Errorf("%w: %T", ErrNoHashKey, msg)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	ring := r.ringFor(routees)
+	hash := hashOf(key)
+	i := sort.Search(len(ring), func(i int) bool { return ring[i].hash >= hash })
+	if i == len(ring) {
+		i = 0
+	}
+	return []Actor{routees[ring[i].index]}, nil
+}
+
+// ringFor returns the hash ring of routees, rebuilding it when they changed.
+// Nodes refer to routees by index so restarted routees are picked up.
+func (r *ConsistentHashRouting) ringFor(routees []Actor) []ringNode {
+	members := ""
+	for _, routee := range routees {
+		members += routeeKey(routee) + ","
+	}
+	if members == r.members {
+		return r.ring
+	}
+
+	ring := make([]ringNode, 0, len(routees)*virtualNodes)
+	for index, routee := range routees {
+		key := routeeKey(routee)
+		for i := 0; i < virtualNodes; i++ {
+			ring = append(ring, ringNode{hash: hashOf(key + "#" + strconv.Itoa(i)), index: index})
+		}
+	}
+	sort.Slice(ring, func(i, j int) bool { return ring[i].hash < ring[j].hash })
+	r.members, r.ring = members, ring
+	return ring
+}
+
+// routeeKey identifies a routee on the hash ring by its path, which survives
+// the actor being recreated, or its ID
+func routeeKey(routee Actor) string {
+	if b := asBasicActor(routee); b != nil && b.path != "" {
+		return b.path
+	}
+	return routee.GetID().String()
+}
+
+// hashOf hashes key with FNV-1a, mixing the result with the murmur3
+// finalizer so keys differing only in their last characters spread over the
+// whole ring
+func hashOf(key string) uint32 {
+	h := fnv.New32a()
+	h.Write([]byte(key))
+	sum := h.Sum32()
+	sum ^= sum >> 16
+	sum *= 0x85ebca6b
+	sum ^= sum >> 13
+	sum *= 0xc2b2ae35
+	sum ^= sum >> 16
+	return sum
+}
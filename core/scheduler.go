@@ -0,0 +1,171 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Cancellable is a scheduled delivery that can be cancelled
+type Cancellable interface {
+	// Cancel stops future deliveries, returning false if already cancelled
+	Cancel() bool
+	// Cancelled reports whether Cancel was called or the scheduler stopped
+	Cancelled() bool
+}
+
+// Scheduler delivers messages to actors after a delay, at a fixed interval or
+// following a cron expression
+type Scheduler struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// NewScheduler creates a scheduler whose deliveries stop when ctx is done or
+// Stop is called
+func NewScheduler(ctx context.Context) *Scheduler {
+	ctx, cancel := context.WithCancel(ctx)
+	return &Scheduler{ctx: ctx, cancel: cancel}
+}
+
+// ScheduleOnce sends msg to actor after delay
+func (s *Scheduler) ScheduleOnce(delay time.Duration, actor Actor, msg interface{}) Cancellable {
+	return scheduleOnce(s.ctx, delay, actor, msg)
+}
+
+// ScheduleRepeatedly sends msg to actor after initialDelay and then every
+// interval until cancelled. A non-positive interval is logged and nothing is
+// scheduled: the returned Cancellable is already cancelled.
+func (s *Scheduler) ScheduleRepeatedly(initialDelay, interval time.Duration, actor Actor, msg interface{}) Cancellable {
+	if interval <= 0 {
+		fmt.Printf("Scheduler ignoring delivery of %T to actor %s: interval %s is not positive\n", msg, actor.GetID(), interval)
+		c := newScheduled(s.ctx)
+		c.Cancel()
+		return c
+	}
+	return schedule(s.ctx, initialDelay, interval, actor, msg)
+}
+
+// ScheduleCron sends msg to actor at the times matched by a cron expression,
+// evaluated in local time. See ParseCron for the supported syntax.
+func (s *Scheduler) ScheduleCron(expression string, actor Actor, msg interface{}) (Cancellable, error) {
+	cron, err := ParseCron(expression)
+	if err != nil {
+		return nil, err
+	}
+
+	c := newScheduled(s.ctx)
+	go func() {
+		for {
+			now := time.Now()
+			next := cron.Next(now)
+			if next.IsZero() {
+				return
+			}
+			timer := time.NewTimer(next.Sub(now))
+			select {
+			case <-timer.C:
+				c.deliver(actor, msg)
+			case <-c.cancelled:
+				timer.Stop()
+				return
+			case <-c.ctx.Done():
+				timer.Stop()
+				return
+			}
+		}
+	}()
+	return c, nil
+}
+
+// Stop cancels every delivery scheduled by this scheduler
+func (s *Scheduler) Stop() {
+	s.cancel()
+}
+
+// scheduleOnce sends msg to actor after delay
+func scheduleOnce(ctx context.Context, delay time.Duration, actor Actor, msg interface{}) *scheduled {
+	c := newScheduled(ctx)
+	go func() {
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+			c.deliver(actor, msg)
+		case <-c.cancelled:
+		case <-c.ctx.Done():
+		}
+	}()
+	return c
+}
+
+// schedule sends msg to actor after initialDelay and then every interval,
+// which must be positive
+func schedule(ctx context.Context, initialDelay, interval time.Duration, actor Actor, msg interface{}) *scheduled {
+	c := newScheduled(ctx)
+	go func() {
+		timer := time.NewTimer(initialDelay)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+			c.deliver(actor, msg)
+		case <-c.cancelled:
+			return
+		case <-c.ctx.Done():
+			return
+		}
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				c.deliver(actor, msg)
+			case <-c.cancelled:
+				return
+			case <-c.ctx.Done():
+				return
+			}
+		}
+	}()
+	return c
+}
+
+// scheduled implements Cancellable
+type scheduled struct {
+	ctx       context.Context
+	once      sync.Once
+	cancelled chan struct{}
+}
+
+func newScheduled(ctx context.Context) *scheduled {
+	return &scheduled{ctx: ctx, cancelled: make(chan struct{})}
+}
+
+func (c *scheduled) Cancel() bool {
+	first := false
+	c.once.Do(func() {
+		close(c.cancelled)
+		first = true
+	})
+	return first
+}
+
+func (c *scheduled) Cancelled() bool {
+	select {
+	case <-c.cancelled:
+		return true
+	case <-c.ctx.Done():
+		return true
+	default:
+		return false
+	}
+}
+
+// deliver sends msg unless the delivery was cancelled meanwhile
+func (c *scheduled) deliver(actor Actor, msg interface{}) {
+	if !c.Cancelled() {
+		actor.SendMessage(msg)
+	}
+}
@@ -0,0 +1,204 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func newRecordingActor(name string) (*BasicActor, chan interface{}) {
+	received := make(chan interface{}, 100)
+	actor := NewBasicActor(name)
+	actor.ReceiveFunc = func(result *ActorResult) *ActorResult {
+		received <- result.Message
+		return &ActorResult{}
+	}
+	return actor, received
+}
+
+func expectNoMessage(t *testing.T, received chan interface{}, wait time.Duration) {
+	t.Helper()
+	select {
+	case msg := <-received:
+		t.Errorf("expected no message, got %v", msg)
+	case <-time.After(wait):
+	}
+}
+
+// Test suite for Scheduler, cron expressions and actor timers
+func TestScheduler(t *testing.T) {
+
+	t.Run("TestScheduleOnce", func(t *testing.T) {
+		// Arrange
+		scheduler := NewScheduler(context.Background())
+		defer scheduler.Stop()
+		actor, received := newRecordingActor("once")
+		actor.Start()
+		defer actor.Stop()
+
+		// Act
+		scheduler.ScheduleOnce(10*time.Millisecond, actor, "wake up")
+		cancelled := scheduler.ScheduleOnce(10*time.Millisecond, actor, "never")
+		cancelled.Cancel()
+
+		// Assert
+		expectMessages(t, received, "wake up")
+		expectNoMessage(t, received, 30*time.Millisecond)
+		if !cancelled.Cancelled() || cancelled.Cancel() {
+			t.Errorf("expected the delivery to be cancelled exactly once")
+		}
+	})
+
+	t.Run("TestScheduleRepeatedly", func(t *testing.T) {
+		// Arrange
+		scheduler := NewScheduler(context.Background())
+		actor, received := newRecordingActor("repeated")
+		actor.Start()
+		defer actor.Stop()
+
+		// Act
+		scheduler.ScheduleRepeatedly(0, 5*time.Millisecond, actor, "tick")
+
+		// Assert
+		expectMessages(t, received, "tick", "tick", "tick")
+		scheduler.Stop()
+		time.Sleep(10 * time.Millisecond)
+		for len(received) > 0 {
+			<-received
+		}
+		expectNoMessage(t, received, 20*time.Millisecond)
+	})
+
+	t.Run("TestNonPositiveIntervalIsIgnored", func(t *testing.T) {
+		// Arrange
+		scheduler := NewScheduler(context.Background())
+		defer scheduler.Stop()
+		actor, received := newRecordingActor("repeated")
+		actor.Start()
+		defer actor.Stop()
+
+		// Act
+		scheduled := scheduler.ScheduleRepeatedly(0, 0, actor, "tick")
+		actor.StartTimer("tick", "tick", -time.Second)
+
+		// Assert
+		if !scheduled.Cancelled() {
+			t.Errorf("expected the delivery not to be scheduled")
+		}
+		expectNoMessage(t, received, 20*time.Millisecond)
+	})
+
+	t.Run("TestScheduleCron", func(t *testing.T) {
+		// Arrange
+		scheduler := NewScheduler(context.Background())
+		defer scheduler.Stop()
+		actor, received := newRecordingActor("cron")
+		actor.Start()
+		defer actor.Stop()
+
+		// Act
+		job, err := scheduler.ScheduleCron("@every 5ms", actor, "report")
+		_, errInvalid := scheduler.ScheduleCron("61 * * * *", actor, "never")
+
+		// Assert
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		expectMessages(t, received, "report", "report")
+		job.Cancel()
+		if errInvalid == nil {
+			t.Errorf("expected an invalid expression to be rejected")
+		}
+	})
+
+	t.Run("TestCronNext", func(t *testing.T) {
+		// Saturday 2024-06-15 10:07:30 UTC
+		now := time.Date(2024, 6, 15, 10, 7, 30, 0, time.UTC)
+		cases := []struct {
+			expression string
+			expected   time.Time
+		}{
+			{"*/15 * * * *", time.Date(2024, 6, 15, 10, 15, 0, 0, time.UTC)},
+			{"0 9 * * mon-fri", time.Date(2024, 6, 17, 9, 0, 0, 0, time.UTC)},
+			{"30 8 1,20 * *", time.Date(2024, 6, 20, 8, 30, 0, 0, time.UTC)},
+			{"0 0 1 * 3", time.Date(2024, 6, 19, 0, 0, 0, 0, time.UTC)},
+			{"0 12 * jan *", time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)},
+			{"0 0 * * 7", time.Date(2024, 6, 16, 0, 0, 0, 0, time.UTC)},
+			{"@hourly", time.Date(2024, 6, 15, 11, 0, 0, 0, time.UTC)},
+			{"@every 90s", now.Add(90 * time.Second)},
+			{"0 0 30 2 *", time.Time{}},
+		}
+		for _, c := range cases {
+			// Arrange
+			cron, err := ParseCron(c.expression)
+			if err != nil {
+				t.Fatalf("unexpected error for %q: %v", c.expression, err)
+			}
+
+			// Act
+			next := cron.Next(now)
+
+			// Assert
+			if !next.Equal(c.expected) {
+				t.Errorf("expected %q to fire at %v, got %v", c.expression, c.expected, next)
+			}
+		}
+	})
+
+	t.Run("TestParseCronErrors", func(t *testing.T) {
+		for _, expression := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "5-1 * * * *", "*/0 * * * *", "* * * foo *", "@every -1s"} {
+			if _, err := ParseCron(expression); err == nil {
+				t.Errorf("expected %q to be rejected", expression)
+			}
+		}
+	})
+
+	t.Run("TestSingleTimer", func(t *testing.T) {
+		// Arrange
+		actor, received := newRecordingActor("single")
+		actor.Start()
+		defer actor.Stop()
+
+		// Act
+		actor.StartSingleTimer("once", "fired", 5*time.Millisecond)
+		actor.StartSingleTimer("replaced", "old", 20*time.Millisecond)
+		actor.StartSingleTimer("replaced", "new", 10*time.Millisecond)
+
+		// Assert
+		expectMessages(t, received, "fired", "new")
+		expectNoMessage(t, received, 30*time.Millisecond)
+	})
+
+	t.Run("TestTimersCancelledOnRestart", func(t *testing.T) {
+		// Arrange
+		supervisor := NewSupervisor(context.Background())
+		defer supervisor.Stop()
+		received := make(chan interface{}, 100)
+		actor := NewBasicActor("restarting")
+		actor.ReceiveFunc = func(result *ActorResult) *ActorResult {
+			switch result.Message {
+			case "start":
+				result.Context().StartTimer("tick", "tick", 5*time.Millisecond)
+			case "fail":
+				return &ActorResult{Error: errors.New("boom")}
+			default:
+				received <- result.Message
+			}
+			return &ActorResult{}
+		}
+		supervisor.SuperviseActor(actor)
+		actor.SendMessage("start")
+		expectMessages(t, received, "tick")
+
+		// Act
+		actor.SendMessage("fail")
+
+		// Assert
+		time.Sleep(20 * time.Millisecond)
+		for len(received) > 0 {
+			<-received
+		}
+		expectNoMessage(t, received, 30*time.Millisecond)
+	})
+}
@@ -0,0 +1,92 @@
+package core
+
+import (
+	"errors"
+)
+
+var (
+	// ErrStashFull is returned by Stash when the stash is at capacity
+	ErrStashFull = errors.New("stash full")
+	// ErrNothingToStash is returned by Stash outside message processing
+	ErrNothingToStash = errors.New("no message being processed")
+)
+
+const defaultStashCapacity = 100
+
+// SetStashCapacity bounds how many messages can be stashed, 100 by default
+func (a *BasicActor) SetStashCapacity(capacity int) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.stashCapacity = capacity
+}
+
+// Stash defers the message being processed until UnstashAll is called, e.g.
+// while the actor is still initializing. It must be called from the receive
+// function.
+func (a *BasicActor) Stash() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.current == nil {
+		return ErrNothingToStash
+	}
+	capacity := a.stashCapacity
+	if capacity <= 0 {
+		capacity = defaultStashCapacity
+	}
+	if len(a.stash) >= capacity {
+		return ErrStashFull
+	}
+	a.stash = append(a.stash, a.current)
+	a.current = nil
+	return nil
+}
+
+// UnstashAll puts the stashed messages back in front of the mailbox, in the
+// order they were stashed
+func (a *BasicActor) UnstashAll() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.unstash()
+}
+
+// unstash moves the stash ahead of the messages already unstashed, callers
+// must hold a.mu
+func (a *BasicActor) unstash() {
+	if len(a.stash) == 0 {
+		return
+	}
+	a.unstashed = append(a.stash, a.unstashed...)
+	a.stash = nil
+}
+
+// StashSize returns the number of stashed messages
+func (a *BasicActor) StashSize() int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return len(a.stash)
+}
+
+// dequeueUnstashed returns the next unstashed message
+func (a *BasicActor) dequeueUnstashed() (interface{}, bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if len(a.unstashed) == 0 {
+		return nil, false
+	}
+	msg := a.unstashed[0]
+	a.unstashed[0] = nil
+	a.unstashed = a.unstashed[1:]
+	return msg, true
+}
+
+// discardStash drops the stashed and unstashed messages as dead letters
+func (a *BasicActor) discardStash() {
+	a.mu.Lock()
+	a.unstash()
+	messages := a.unstashed
+	a.unstashed = nil
+	a.mu.Unlock()
+	for _, msg := range messages {
+		a.publishDeadLetter(msg, ErrActorRestarted)
+	}
+}
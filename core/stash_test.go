@@ -0,0 +1,193 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func expectMessages(t *testing.T, received chan interface{}, expected ...interface{}) {
+	t.Helper()
+	for _, want := range expected {
+		select {
+		case got := <-received:
+			if got != want {
+				t.Errorf("expected %v, got %v", want, got)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("expected %v to be processed", want)
+		}
+	}
+}
+
+// Test suite for Stash and UnstashAll
+func TestStash(t *testing.T) {
+
+	t.Run("TestStashUntilInitialized", func(t *testing.T) {
+		// Arrange
+		received := make(chan interface{}, 10)
+		actor := NewBasicActor("loader")
+		ready := func(result *ActorResult) *ActorResult {
+			received <- result.Message
+			return &ActorResult{}
+		}
+		actor.ReceiveFunc = func(result *ActorResult) *ActorResult {
+			if result.Message == "loaded" {
+				actor.UnstashAll()
+				result.Become(ready)
+				return &ActorResult{}
+			}
+			if err := actor.Stash(); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			return &ActorResult{}
+		}
+
+		// Act
+		actor.SendMessage("first")
+		actor.SendMessage("second")
+		actor.SendMessage("loaded")
+		actor.SendMessage("third")
+		actor.Start()
+		defer actor.Stop()
+
+		// Assert
+		expectMessages(t, received, "first", "second", "third")
+	})
+
+	t.Run("TestStashKeepsSender", func(t *testing.T) {
+		// Arrange
+		actor := NewBasicActor("replier")
+		ready := func(result *ActorResult) *ActorResult {
+			result.Reply("pong")
+			return &ActorResult{}
+		}
+		actor.ReceiveFunc = func(result *ActorResult) *ActorResult {
+			if result.Message == "loaded" {
+				actor.UnstashAll()
+				result.Become(ready)
+				return &ActorResult{}
+			}
+			actor.Stash()
+			return &ActorResult{}
+		}
+		actor.Start()
+		defer actor.Stop()
+
+		// Act
+		future, _ := Ask(context.Background(), actor, "ping")
+		actor.SendMessage("loaded")
+
+		// Assert
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		if reply, err := future.Await(ctx); err != nil || reply != "pong" {
+			t.Errorf("expected the stashed Ask to be answered, got %v (%v)", reply, err)
+		}
+	})
+
+	t.Run("TestStashCapacity", func(t *testing.T) {
+		// Arrange
+		errs := make(chan error, 3)
+		actor := NewBasicActor("small-stash")
+		actor.SetStashCapacity(2)
+		actor.ReceiveFunc = func(result *ActorResult) *ActorResult {
+			errs <- actor.Stash()
+			return &ActorResult{}
+		}
+		actor.Start()
+		defer actor.Stop()
+
+		// Act
+		for i := 0; i < 3; i++ {
+			actor.SendMessage(i)
+		}
+
+		// Assert
+		for i := 0; i < 3; i++ {
+			err := <-errs
+			if i < 2 && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if i == 2 && !errors.Is(err, ErrStashFull) {
+				t.Errorf("expected ErrStashFull, got %v", err)
+			}
+		}
+		if actor.StashSize() != 2 {
+			t.Errorf("expected 2 stashed messages, got %d", actor.StashSize())
+		}
+	})
+
+	t.Run("TestStashOutsideReceive", func(t *testing.T) {
+		// Arrange
+		actor := NewBasicActor("idle")
+
+		// Act
+		err := actor.Stash()
+
+		// Assert
+		if !errors.Is(err, ErrNothingToStash) {
+			t.Errorf("expected ErrNothingToStash, got %v", err)
+		}
+	})
+
+	t.Run("TestStashSurvivesRestart", func(t *testing.T) {
+		// Arrange
+		received := make(chan interface{}, 10)
+		incarnation := 0
+		supervisor := NewSupervisor(context.Background())
+		defer supervisor.Stop()
+		actor := supervisor.SuperviseProps(PropsFromProducer(func() Actor {
+			incarnation++
+			a := NewBasicActor("stashing")
+			first := incarnation == 1
+			a.ReceiveFunc = func(result *ActorResult) *ActorResult {
+				switch {
+				case result.Message == "fail":
+					return &ActorResult{Error: errors.New("initialization failed")}
+				case first:
+					a.Stash()
+				default:
+					received <- result.Message
+				}
+				return &ActorResult{}
+			}
+			return a
+		}), "stashing")
+
+		// Act
+		actor.SendMessage("first")
+		actor.SendMessage("second")
+		actor.SendMessage("fail")
+		actor.SendMessage("third")
+
+		// Assert
+		expectMessages(t, received, "first", "second", "third")
+	})
+
+	t.Run("TestStashGoesToDeadLettersOnStop", func(t *testing.T) {
+		// Arrange
+		deadLetters, letters := collectDeadLetters(t)
+		stashed := make(chan struct{}, 1)
+		actor := NewBasicActor("stopping")
+		actor.SetDeadLetters(deadLetters)
+		actor.ReceiveFunc = func(result *ActorResult) *ActorResult {
+			actor.Stash()
+			stashed <- struct{}{}
+			return &ActorResult{}
+		}
+		actor.Start()
+		actor.SendMessage("deferred")
+		<-stashed
+
+		// Act
+		actor.Stop()
+
+		// Assert
+		letter := expectDeadLetter(t, letters)
+		if letter.Message != "deferred" || !errors.Is(letter.Reason, ErrActorStopped) {
+			t.Errorf("expected the stashed message to become a dead letter, got %v (%v)", letter.Message, letter.Reason)
+		}
+	})
+}
@@ -3,7 +3,9 @@ package core
 import (
 	"context"
 	"fmt"
+	"sort"
 	"sync"
+	"time"
 
 	"github.com/google/uuid"
 )
@@ -11,12 +13,29 @@ import (
 type SupervisorActorResult struct {
 	Action int
 	Result *ActorResult
+	// SupervisorID identifies the sub-supervisor reporting the failure
+	SupervisorID uuid.UUID
+}
+
+// childSpec is a supervised actor and, when known, the props to rebuild it
+type childSpec struct {
+	actor     Actor
+	props     *Props
+	startedAt time.Time
+	restarts  int // Consecutive restarts, used for backoff
 }
 
 type Supervisor struct {
 	id                uuid.UUID // UUID for each supervisor
 	child             bool
-	actors            map[uuid.UUID]Actor // Changed to a map with actor name as key
+	parent            *Supervisor
+	decider           func(result *SupervisorActorResult) int
+	onTerminalFailure func(result *SupervisorActorResult)
+	strategy          int
+	intensity         restartIntensity
+	backoff           *BackoffOptions
+	mu                sync.RWMutex
+	children          []*childSpec // Ordered by start time
 	subSupervisors    map[uuid.UUID]*Supervisor
 	stop              chan struct{}
 	wg                sync.WaitGroup
@@ -31,7 +50,6 @@ func NewSupervisor(ctx context.Context) *Supervisor {
 	ctx, cancel := context.WithCancel(ctx)
 	s := &Supervisor{
 		id:             uuid.New(),
-		actors:         make(map[uuid.UUID]Actor),
 		subSupervisors: make(map[uuid.UUID]*Supervisor),
 		stop:           make(chan struct{}),
 		ctx:            ctx,
@@ -43,27 +61,145 @@ func NewSupervisor(ctx context.Context) *Supervisor {
 	return s
 }
 
+// NewSupervisorWithStrategy creates a new supervisor using the given
+// supervision strategy (ONE_FOR_ONE, ONE_FOR_ALL or REST_FOR_ONE)
+func NewSupervisorWithStrategy(ctx context.Context, strategy int) *Supervisor {
+	s := NewSupervisor(ctx)
+	s.strategy = strategy
+	return s
+}
+
 func (s *Supervisor) GetID() uuid.UUID {
 	return s.id
 }
 
+// SetStrategy sets which children are restarted when one of them fails
+func (s *Supervisor) SetStrategy(strategy int) {
+	s.strategy = strategy
+}
+
+// SetRestartIntensity limits the supervisor to maxRestarts restarts within
+// the given window. Once exceeded the failure is escalated to the parent
+// supervisor, or all children are stopped if there is none.
+func (s *Supervisor) SetRestartIntensity(maxRestarts int, within time.Duration) {
+	s.intensity = restartIntensity{
+		maxRestarts: maxRestarts,
+		within:      within,
+	}
+}
+
+// SetBackoff delays restarts of failing children with exponential backoff
+func (s *Supervisor) SetBackoff(options BackoffOptions) {
+	s.backoff = &options
+}
+
+// SetDecider sets how failures escalated by sub-supervisors are handled. The
+// decider returns SUPERVISOR_RESTART, SUPERVISOR_STOP, SUPERVISOR_IGNORE or
+// SUPERVISOR_FAIL to escalate further; the default restarts the subtree.
+func (s *Supervisor) SetDecider(decider func(result *SupervisorActorResult) int) {
+	s.decider = decider
+}
+
+// SetTerminalFailureHandler sets the callback invoked when a failure reaches
+// this supervisor and it has no parent to escalate to
+func (s *Supervisor) SetTerminalFailureHandler(handler func(result *SupervisorActorResult)) {
+	s.onTerminalFailure = handler
+}
+
+// Children returns the supervised actors in the order they were started
+func (s *Supervisor) Children() []Actor {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	actors := make([]Actor, 0, len(s.children))
+	for _, spec := range s.children {
+		actors = append(actors, spec.actor)
+	}
+	return actors
+}
+
 // SuperviseActor adds an actor to the supervisor and starts it
 func (s *Supervisor) SuperviseActor(actor Actor) {
+	s.superviseActor(actor, nil)
+}
+
+// SuperviseProps creates an actor from props and supervises it. Restarts
+// build a fresh instance from the same props instead of reusing the old one.
+func (s *Supervisor) SuperviseProps(props *Props, name string) Actor {
+	actor := props.newActor()
+	if b := asBasicActor(actor); b != nil {
+		b.name = name
+	}
+	s.superviseActor(actor, props)
+	return actor
+}
+
+func (s *Supervisor) superviseActor(actor Actor, props *Props) {
 	fmt.Println("Supervisor supervising actor...")
+	s.mu.Lock()
+	s.children = append(s.children, &childSpec{actor: actor, props: props, startedAt: time.Now()})
+	s.mu.Unlock()
+	s.startActor(actor)
+}
+
+func (s *Supervisor) startActor(actor Actor) {
+	if b := asBasicActor(actor); b != nil {
+		b.supervised = true
+		b.mu.Lock()
+		b.self = actor
+		b.mu.Unlock()
+		p := b.sharedPassivation()
+		p.mu.Lock()
+		p.reactivate = func() { s.reactivate(b.id) }
+		p.mu.Unlock()
+	}
 	actor.SetWaitGroup(&s.wg)
 	actor.SetContext(s.ctx)
 	actor.SetFailureChannel(s.actorMonitor.GetInboundChannel())
-	s.actors[actor.GetID()] = actor
 	actor.Start()
 }
 
-// SuperviseSupervisor adds a nested supervisor (creating a hierarchy)
+// StopActor stops a supervised actor and stops supervising it
+func (s *Supervisor) StopActor(actor Actor) {
+	s.mu.Lock()
+	if i := s.indexOf(actor.GetID()); i >= 0 {
+		actor = s.children[i].actor
+		s.children = append(s.children[:i:i], s.children[i+1:]...)
+	}
+	s.mu.Unlock()
+	actor.Stop()
+}
+
+// SuperviseSupervisor adds a nested supervisor (creating a hierarchy). Its
+// escalated failures are handled by this supervisor's decider and it is
+// stopped together with this supervisor.
 func (s *Supervisor) SuperviseSupervisor(subSupervisor *Supervisor) {
 	fmt.Println("Supervisor supervising sub-core...")
-	subSupervisor.ctx = s.ctx
 	subSupervisor.child = true
+	subSupervisor.parent = s
 	subSupervisor.supervisorMonitor.SetOutboundChannel(s.supervisorMonitor.GetInboundChannel())
+	s.mu.Lock()
 	s.subSupervisors[subSupervisor.GetID()] = subSupervisor
+	s.mu.Unlock()
+
+	// Watch the parent so the subtree goes down with it
+	go func() {
+		select {
+		case <-s.ctx.Done():
+			subSupervisor.Stop()
+		case <-subSupervisor.stop:
+		}
+	}()
+}
+
+// SubSupervisors returns the nested supervisors
+func (s *Supervisor) SubSupervisors() []*Supervisor {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	subSupervisors := make([]*Supervisor, 0, len(s.subSupervisors))
+	for _, subSupervisor := range s.subSupervisors {
+		subSupervisors = append(subSupervisors, subSupervisor)
+	}
+	return subSupervisors
 }
 
 // Stop gracefully stops all actors and nested supervisors
@@ -72,13 +208,15 @@ func (s *Supervisor) Stop() {
 
 	s.cancel()
 
-	// Signal all actors to stop
-	for _, actor := range s.actors {
-		actor.Stop()
+	// Signal all actors to stop, last started first
+	s.mu.RLock()
+	for i := len(s.children) - 1; i >= 0; i-- {
+		s.children[i].actor.Stop()
 	}
+	s.mu.RUnlock()
 
 	// Signal all sub-supervisors to stop
-	for _, subSupervisor := range s.subSupervisors {
+	for _, subSupervisor := range s.SubSupervisors() {
 		subSupervisor.Stop()
 	}
 
@@ -97,7 +235,12 @@ func (s *Supervisor) Stop() {
 		fmt.Println("Context canceled or timeout reached before all actors could stop.")
 	}
 
-	close(s.stop)
+	select {
+	case <-s.stop:
+		// Already stopped
+	default:
+		close(s.stop)
+	}
 }
 
 // Wait blocks until the supervisor is stopped
@@ -107,53 +250,319 @@ func (s *Supervisor) Wait() {
 }
 
 func (s *Supervisor) handleActorFailure(result *ActorResult) {
-	actor := s.findActor(result.ID)
+	// Actors that are not restarted carry on with their next message
+	defer result.resumeActor()
+
+	affected := s.affectedChildren(result.ID)
+	if len(affected) == 0 {
+		fmt.Printf("Supervisor %s received failure for unknown actor %s\n", s.id, result.ID)
+		return
+	}
+
+	if result.Action == ACTOR_RESTART || result.Action == ACTOR_RETRY {
+		s.mu.Lock()
+		allowed := s.intensity.allow(time.Now())
+		s.mu.Unlock()
+		if !allowed {
+			fmt.Printf("Supervisor %s exceeded its restart intensity, escalating...\n", s.id)
+			s.escalate(result)
+			return
+		}
+	}
 
 	switch result.Action {
 	case ACTOR_RESTART:
 		fmt.Println("Restarting actor due to critical error...")
-		actor.Stop()
-		actor.Start()
+		s.restartChildren(affected, result, nil)
 
 	case ACTOR_RETRY:
 		fmt.Println("Retrying the failed message...")
-		actor.Stop()
-		actor.SendMessage(result.Message)
-		actor.Start()
+		failed := affected[0]
+		s.restartChildren(affected, result, func() {
+			// A blocking mailbox must not stall the monitor goroutine, which
+			// the actor may be waiting on to report its next failure
+			go failed.actor.SendMessage(result.Message)
+		})
 
 	case ACTOR_FAIL:
 		fmt.Println("Propagating failure to parent core...")
 		// If this supervisor is a child, propagate the failure upwards
 		if s.child {
 			s.reportErrorToParent(result)
+		} else {
+			s.terminalFailure(&SupervisorActorResult{Action: SUPERVISOR_FAIL, Result: result, SupervisorID: s.id})
 		}
 	}
 }
 
+// escalate hands a failure the supervisor cannot handle to its parent. A
+// top-level supervisor reports it as terminal and stops all of its children.
+func (s *Supervisor) escalate(result *ActorResult) {
+	if s.child {
+		s.reportErrorToParent(result)
+		return
+	}
+	fmt.Printf("Supervisor %s has no parent, stopping all children...\n", s.id)
+	s.terminalFailure(&SupervisorActorResult{Action: SUPERVISOR_FAIL, Result: result, SupervisorID: s.id})
+	s.Stop()
+}
+
+// terminalFailure reports a failure that cannot be escalated any further
+func (s *Supervisor) terminalFailure(result *SupervisorActorResult) {
+	fmt.Printf("Supervisor %s reached a terminal failure: %v\n", s.id, result.Result.Error)
+	if s.onTerminalFailure != nil {
+		s.onTerminalFailure(result)
+	}
+}
+
+// affectedChildren returns the children restarted by the supervision strategy
+// when the actor with the given id fails, the failed child first
+func (s *Supervisor) affectedChildren(id uuid.UUID) []*childSpec {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	i := s.indexOf(id)
+	if i < 0 {
+		return nil
+	}
+
+	affected := []*childSpec{s.children[i]}
+	switch s.strategy {
+	case ONE_FOR_ALL:
+		affected = append(affected, s.children[:i]...)
+		affected = append(affected, s.children[i+1:]...)
+	case REST_FOR_ONE:
+		affected = append(affected, s.children[i+1:]...)
+	}
+	return affected
+}
+
+// restartChildren stops the given children, last started first, and then
+// starts their next incarnations in start order, after the backoff delay if
+// one is configured. The first child is the one that failed with failure.
+// onRestarted, if set, runs once they are started.
+func (s *Supervisor) restartChildren(specs []*childSpec, failure *ActorResult, onRestarted func()) {
+	delay := s.restartDelay(specs[0])
+	causes := make(map[*childSpec]*restartCause, len(specs))
+	for _, spec := range specs {
+		causes[spec] = &restartCause{reason: failure.Error}
+	}
+	causes[specs[0]].message = failure.Message
+
+	ordered := make([]*childSpec, len(specs))
+	copy(ordered, specs)
+	s.mu.RLock()
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return s.indexOf(ordered[i].actor.GetID()) < s.indexOf(ordered[j].actor.GetID())
+	})
+	s.mu.RUnlock()
+
+	for i := len(ordered) - 1; i >= 0; i-- {
+		s.stopChild(ordered[i], causes[ordered[i]])
+	}
+
+	start := func() {
+		if s.ctx.Err() != nil {
+			return
+		}
+		for _, spec := range ordered {
+			s.mu.RLock()
+			supervised := s.indexOf(spec.actor.GetID()) >= 0
+			s.mu.RUnlock()
+			if supervised {
+				s.startNextIncarnation(spec, causes[spec])
+			}
+		}
+		if onRestarted != nil {
+			onRestarted()
+		}
+	}
+	if delay <= 0 {
+		start()
+		return
+	}
+	fmt.Printf("Supervisor %s restarting in %s...\n", s.id, delay)
+	time.AfterFunc(delay, start)
+}
+
+// restartDelay returns the backoff before restarting the failed child and
+// counts the restart
+func (s *Supervisor) restartDelay(failed *childSpec) time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.backoff == nil {
+		return 0
+	}
+	if s.backoff.ResetAfter > 0 && time.Since(failed.startedAt) >= s.backoff.ResetAfter {
+		failed.restarts = 0
+	}
+	delay := s.backoff.delay(failed.restarts)
+	failed.restarts++
+	return delay
+}
+
+// stopChild stops a child for a restart and waits for its run loop to finish
+func (s *Supervisor) stopChild(spec *childSpec, cause *restartCause) {
+	if b := asBasicActor(spec.actor); b != nil {
+		b.mu.Lock()
+		b.restarting = cause
+		b.mu.Unlock()
+	}
+	spec.actor.Stop()
+	if b := asBasicActor(spec.actor); b != nil {
+		b.awaitTermination()
+	}
+}
+
+// startNextIncarnation starts a stopped child again: a fresh instance when it
+// was supervised from Props, or the same instance with a new stop channel
+// otherwise. cause is nil when a passivated child is reactivated, which keeps
+// its mailbox.
+func (s *Supervisor) startNextIncarnation(spec *childSpec, cause *restartCause) {
+	next := spec.actor
+	if spec.props != nil {
+		next = spec.props.reincarnate(spec.actor)
+		if b := asBasicActor(next); b != nil && cause != nil && spec.props.discardMailbox {
+			b.drainMailbox()
+		}
+	} else if b := asBasicActor(next); b != nil {
+		b.reincarnate()
+	}
+
+	s.mu.Lock()
+	spec.actor = next
+	spec.startedAt = time.Now()
+	s.mu.Unlock()
+	if b := asBasicActor(next); b != nil {
+		b.restartedFrom = cause
+		if b.system != nil && b.path != "" {
+			b.system.registry.replaceActor(b.path, next)
+		}
+		if parent := b.getParent(); parent != nil {
+			parent.replaceChild(b)
+		}
+	}
+
+	s.startActor(next)
+}
+
+// reactivate starts the next incarnation of a passivated child
+func (s *Supervisor) reactivate(id uuid.UUID) {
+	s.mu.RLock()
+	var spec *childSpec
+	if i := s.indexOf(id); i >= 0 {
+		spec = s.children[i]
+	}
+	s.mu.RUnlock()
+	if spec == nil || s.ctx.Err() != nil {
+		return
+	}
+	if b := asBasicActor(spec.actor); b != nil {
+		b.awaitTermination()
+	}
+	s.startNextIncarnation(spec, nil)
+}
+
 func (s *Supervisor) reportErrorToParent(result *ActorResult) {
-	s.supervisorMonitor.GetOutboundChannel() <- &SupervisorActorResult{
-		Action: SUPERVISOR_FAIL,
-		Result: result,
+	select {
+	case s.supervisorMonitor.GetOutboundChannel() <- &SupervisorActorResult{
+		Action:       SUPERVISOR_FAIL,
+		Result:       result,
+		SupervisorID: s.id,
+	}:
+	case <-s.parent.ctx.Done():
 	}
 }
 
 func (s *Supervisor) handleSupervisorFailure(result *SupervisorActorResult) {
+	subSupervisor := s.findSubSupervisor(result.SupervisorID)
+	if subSupervisor == nil {
+		fmt.Printf("Supervisor %s received failure for unknown sub-supervisor %s\n", s.id, result.SupervisorID)
+		return
+	}
 
-	switch result.Action {
+	action := SUPERVISOR_RESTART
+	if s.decider != nil {
+		action = s.decider(result)
+	}
+
+	switch action {
 	case SUPERVISOR_RESTART:
-		fmt.Println("Restarting actor due to critical error...")
-		// TODO restat supervisor
+		fmt.Println("Restarting sub-supervisor due to critical error...")
+		subSupervisor.restartSubtree(result.Result.Error)
+
+	case SUPERVISOR_STOP:
+		fmt.Println("Stopping sub-supervisor due to critical error...")
+		s.mu.Lock()
+		delete(s.subSupervisors, subSupervisor.GetID())
+		s.mu.Unlock()
+		subSupervisor.Stop()
+
+	case SUPERVISOR_IGNORE:
+		fmt.Println("Resuming sub-supervisor...")
 
 	case SUPERVISOR_FAIL:
 		fmt.Println("Propagating failure to parent core...")
-		// TODO RELAY TO NEXT LAYER
+		if s.child {
+			s.reportErrorToParent(result.Result)
+		} else {
+			s.terminalFailure(result)
+		}
+	}
+}
+
+// restartSubtree restarts every actor and nested supervisor below this
+// supervisor and clears its restart history
+func (s *Supervisor) restartSubtree(reason error) {
+	s.mu.RLock()
+	specs := make([]*childSpec, len(s.children))
+	copy(specs, s.children)
+	s.mu.RUnlock()
+
+	for i := len(specs) - 1; i >= 0; i-- {
+		s.stopChild(specs[i], &restartCause{reason: reason})
+	}
+	for _, subSupervisor := range s.SubSupervisors() {
+		subSupervisor.restartSubtree(reason)
+	}
+	for _, spec := range specs {
+		// The child's own failures count restarts on its monitor goroutine
+		s.mu.Lock()
+		spec.restarts = 0
+		s.mu.Unlock()
+		s.startNextIncarnation(spec, &restartCause{reason: reason})
 	}
+
+	s.mu.Lock()
+	s.intensity.restarts = nil
+	s.mu.Unlock()
+}
+
+// findSubSupervisor finds a nested supervisor by ID
+func (s *Supervisor) findSubSupervisor(id uuid.UUID) *Supervisor {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.subSupervisors[id]
 }
 
-// findActor finds an actor by name in the supervisor's map
+// findActor finds a supervised actor by ID
 func (s *Supervisor) findActor(id uuid.UUID) Actor {
-	if actor, exists := s.actors[id]; exists {
-		return actor
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if i := s.indexOf(id); i >= 0 {
+		return s.children[i].actor
+	}
+	return nil
+}
+
+// indexOf returns the position of the child with the given ID, callers must
+// hold s.mu
+func (s *Supervisor) indexOf(id uuid.UUID) int {
+	for i, spec := range s.children {
+		if spec.actor.GetID() == id {
+			return i
+		}
 	}
-	return nil // TODO: Handle if actor is not found, maybe return an error
+	return -1
 }
@@ -0,0 +1,442 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// Test suite for Supervisor
+func TestSupervisor(t *testing.T) {
+
+	t.Run("TestRestartResetsStateFromProps", func(t *testing.T) {
+		// Arrange
+		counts := make(chan int, 10)
+		props := PropsFromProducer(func() Actor {
+			count := 0
+			actor := NewBasicActor("")
+			actor.ReceiveFunc = func(result *ActorResult) *ActorResult {
+				if result.Message == "fail" {
+					return &ActorResult{Error: errors.New("boom"), Action: ACTOR_RESTART}
+				}
+				count++
+				counts <- count
+				return &ActorResult{}
+			}
+			return actor
+		})
+		supervisor := NewSupervisor(context.Background())
+		defer supervisor.Stop()
+		actor := supervisor.SuperviseProps(props, "counter")
+
+		// Act
+		actor.SendMessage("inc")
+		actor.SendMessage("inc")
+		actor.SendMessage("fail")
+		actor.SendMessage("inc")
+
+		// Assert
+		expected := []int{1, 2, 1}
+		for _, want := range expected {
+			select {
+			case got := <-counts:
+				if got != want {
+					t.Errorf("expected count %d, got %d", want, got)
+				}
+			case <-time.After(time.Second):
+				t.Fatalf("expected count %d to be reported", want)
+			}
+		}
+	})
+
+	t.Run("TestRestartWithoutPropsKeepsRunning", func(t *testing.T) {
+		// Arrange
+		received := make(chan interface{}, 10)
+		actor := NewBasicActor("plain-actor")
+		actor.ReceiveFunc = func(result *ActorResult) *ActorResult {
+			received <- result.Message
+			if result.Message == "fail" {
+				return &ActorResult{Error: errors.New("boom"), Action: ACTOR_RESTART}
+			}
+			return &ActorResult{}
+		}
+		supervisor := NewSupervisor(context.Background())
+		defer supervisor.Stop()
+		supervisor.SuperviseActor(actor)
+
+		// Act
+		actor.SendMessage("fail")
+		<-received
+		time.Sleep(50 * time.Millisecond) // Allow the restart to complete
+		actor.SendMessage("after restart")
+
+		// Assert
+		select {
+		case msg := <-received:
+			if msg != "after restart" {
+				t.Errorf("expected 'after restart', got %v", msg)
+			}
+		case <-time.After(time.Second):
+			t.Errorf("expected restarted actor to keep processing messages")
+		}
+	})
+
+	t.Run("TestRetryRedeliversMessage", func(t *testing.T) {
+		// Arrange
+		attempts := make(chan int, 10)
+		props := PropsFromProducer(func() Actor {
+			actor := NewBasicActor("")
+			actor.ReceiveFunc = func(result *ActorResult) *ActorResult {
+				attempts <- 1
+				if len(attempts) < 2 {
+					return &ActorResult{Error: errors.New("transient"), Action: ACTOR_RETRY}
+				}
+				return &ActorResult{}
+			}
+			return actor
+		})
+		supervisor := NewSupervisor(context.Background())
+		defer supervisor.Stop()
+		actor := supervisor.SuperviseProps(props, "retrying")
+
+		// Act
+		actor.SendMessage("job")
+		time.Sleep(100 * time.Millisecond)
+
+		// Assert
+		if len(attempts) != 2 {
+			t.Errorf("expected the message to be retried once, got %d attempts", len(attempts))
+		}
+	})
+
+	t.Run("TestRestartDiscardsMailboxWhenConfigured", func(t *testing.T) {
+		// Arrange
+		received := make(chan interface{}, 10)
+		release := make(chan struct{})
+		deadLetters, letters := collectDeadLetters(t)
+		props := PropsFromProducer(func() Actor {
+			actor := NewBasicActor("")
+			actor.SetDeadLetters(deadLetters)
+			actor.ReceiveFunc = func(result *ActorResult) *ActorResult {
+				if result.Message == "fail" {
+					<-release
+					return &ActorResult{Error: errors.New("boom")}
+				}
+				received <- result.Message
+				return &ActorResult{}
+			}
+			return actor
+		}).WithMailboxCarryOver(false)
+		supervisor := NewSupervisor(context.Background())
+		defer supervisor.Stop()
+		actor := supervisor.SuperviseProps(props, "discarding")
+
+		// Act
+		actor.SendMessage("fail")
+		actor.SendMessage("queued")
+		close(release)
+		letter := expectDeadLetter(t, letters)
+		actor.SendMessage("fresh")
+
+		// Assert
+		if letter.Message != "queued" || !errors.Is(letter.Reason, ErrActorRestarted) {
+			t.Errorf("expected the queued message as a dead letter, got %v (%v)", letter.Message, letter.Reason)
+		}
+		select {
+		case msg := <-received:
+			if msg != "fresh" {
+				t.Errorf("expected queued message to be discarded, got %v", msg)
+			}
+		case <-time.After(time.Second):
+			t.Errorf("expected restarted actor to process new messages")
+		}
+	})
+
+	t.Run("TestRestartUpdatesSystemRegistry", func(t *testing.T) {
+		// Arrange
+		system := NewActorSystem(context.Background(), "test-system")
+		defer system.Shutdown()
+		events := make(chan string, 10)
+		incarnation := 0
+		actor, _ := system.ActorOf(PropsFromProducer(func() Actor {
+			incarnation++
+			return newConnectionActor(incarnation, events)
+		}), "worker")
+		expectEvents(t, events, "PreStart 1")
+
+		// Act
+		actor.SendMessage("fail")
+		expectEvents(t, events, "PreRestart 1: connection lost (fail)", "PostRestart 2: connection lost")
+
+		// Assert
+		current, _ := system.Lookup("/user/worker")
+		if asBasicActor(current) == asBasicActor(actor) {
+			t.Errorf("expected the registry to point at the new incarnation")
+		}
+		if current.GetID() != actor.GetID() {
+			t.Errorf("expected the new incarnation to keep the actor's ID")
+		}
+	})
+
+	t.Run("TestReferenceSurvivesRestart", func(t *testing.T) {
+		// Arrange
+		system := NewActorSystem(context.Background(), "test-system")
+		defer system.Shutdown()
+		received := make(chan interface{}, 10)
+		actor, _ := system.ActorOf(PropsFromFunc(func(result *ActorResult) *ActorResult {
+			received <- result.Message
+			if result.Message == "fail" {
+				return &ActorResult{Error: errors.New("boom"), Action: ACTOR_RESTART}
+			}
+			return &ActorResult{}
+		}), "worker")
+		actor.SendMessage("fail")
+		<-received
+		time.Sleep(50 * time.Millisecond) // Allow the restart to complete
+		watcher, terminated := newWatcher(t)
+
+		// Act
+		Watch(watcher, actor)
+		actor.Stop()
+
+		// Assert
+		if msg := expectTerminated(t, terminated); msg.ID != actor.GetID() {
+			t.Errorf("expected the restarted actor to terminate, got %s", msg.Name)
+		}
+		actor.SendMessage("after stop")
+		expectNoMessage(t, received, 50*time.Millisecond)
+	})
+
+	t.Run("TestSupervisionStrategies", func(t *testing.T) {
+		cases := []struct {
+			name      string
+			strategy  int
+			restarted []bool
+		}{
+			{"OneForOne", ONE_FOR_ONE, []bool{false, true, false}},
+			{"OneForAll", ONE_FOR_ALL, []bool{true, true, true}},
+			{"RestForOne", REST_FOR_ONE, []bool{false, true, true}},
+		}
+
+		for _, tc := range cases {
+			t.Run(tc.name, func(t *testing.T) {
+				// Arrange
+				supervisor := NewSupervisorWithStrategy(context.Background(), tc.strategy)
+				defer supervisor.Stop()
+
+				incarnations := make([]chan struct{}, 3)
+				actors := make([]Actor, 3)
+				for i := range actors {
+					started := make(chan struct{}, 10)
+					incarnations[i] = started
+					actors[i] = supervisor.SuperviseProps(PropsFromProducer(func() Actor {
+						started <- struct{}{}
+						actor := NewBasicActor("")
+						actor.ReceiveFunc = func(result *ActorResult) *ActorResult {
+							return &ActorResult{Error: errors.New("boom")}
+						}
+						return actor
+					}), "child")
+				}
+
+				// Act
+				actors[1].SendMessage("fail")
+				time.Sleep(100 * time.Millisecond)
+
+				// Assert
+				for i, want := range tc.restarted {
+					got := len(incarnations[i]) > 1
+					if got != want {
+						t.Errorf("child %d: expected restarted=%v, got %v", i, want, got)
+					}
+				}
+				children := supervisor.Children()
+				for i := range actors {
+					if children[i].GetID() != actors[i].GetID() {
+						t.Errorf("expected children to keep their start order")
+					}
+				}
+			})
+		}
+	})
+
+	t.Run("TestRestartIntensityStopsChildren", func(t *testing.T) {
+		// Arrange
+		supervisor := NewSupervisor(context.Background())
+		supervisor.SetRestartIntensity(2, time.Second)
+		actor := supervisor.SuperviseProps(PropsFromFunc(func(result *ActorResult) *ActorResult {
+			return &ActorResult{Error: errors.New("boom")}
+		}), "flaky")
+
+		// Act
+		for i := 0; i < 3; i++ {
+			actor.SendMessage("fail")
+		}
+
+		// Assert
+		select {
+		case <-supervisor.stop:
+		case <-time.After(time.Second):
+			t.Errorf("expected supervisor to give up after exceeding its restart intensity")
+		}
+	})
+
+	t.Run("TestBackoffDelaysRestart", func(t *testing.T) {
+		// Arrange
+		started := make(chan time.Time, 10)
+		supervisor := NewSupervisor(context.Background())
+		defer supervisor.Stop()
+		supervisor.SetBackoff(BackoffOptions{
+			MinBackoff: 50 * time.Millisecond,
+			MaxBackoff: time.Second,
+		})
+		actor := supervisor.SuperviseProps(PropsFromProducer(func() Actor {
+			started <- time.Now()
+			actor := NewBasicActor("")
+			actor.ReceiveFunc = func(result *ActorResult) *ActorResult {
+				return &ActorResult{Error: errors.New("boom")}
+			}
+			return actor
+		}), "backing-off")
+		first := <-started
+
+		// Act
+		actor.SendMessage("fail")
+		actor.SendMessage("fail")
+
+		// Assert
+		expected := []time.Duration{50 * time.Millisecond, 150 * time.Millisecond}
+		for _, want := range expected {
+			select {
+			case at := <-started:
+				if elapsed := at.Sub(first); elapsed < want {
+					t.Errorf("expected restart after at least %s, got %s", want, elapsed)
+				}
+			case <-time.After(time.Second):
+				t.Fatalf("expected actor to be restarted")
+			}
+		}
+	})
+
+	t.Run("TestEscalationRestartsSubtree", func(t *testing.T) {
+		// Arrange
+		parent := NewSupervisor(context.Background())
+		defer parent.Stop()
+		child := NewSupervisor(context.Background())
+		parent.SuperviseSupervisor(child)
+
+		started := make(chan string, 10)
+		failing := child.SuperviseProps(PropsFromProducer(func() Actor {
+			started <- "failing"
+			actor := NewBasicActor("")
+			actor.ReceiveFunc = func(result *ActorResult) *ActorResult {
+				return &ActorResult{Error: errors.New("fatal"), Action: ACTOR_FAIL}
+			}
+			return actor
+		}), "failing")
+		child.SuperviseProps(PropsFromProducer(func() Actor {
+			started <- "sibling"
+			return NewBasicActor("")
+		}), "sibling")
+		<-started
+		<-started
+
+		// Act
+		failing.SendMessage("fail")
+
+		// Assert
+		restarted := map[string]bool{}
+		for i := 0; i < 2; i++ {
+			select {
+			case name := <-started:
+				restarted[name] = true
+			case <-time.After(time.Second):
+				t.Fatalf("expected the whole subtree to be restarted")
+			}
+		}
+		if !restarted["failing"] || !restarted["sibling"] {
+			t.Errorf("expected both children to be restarted, got %v", restarted)
+		}
+	})
+
+	t.Run("TestEscalationStopsSubtree", func(t *testing.T) {
+		// Arrange
+		parent := NewSupervisor(context.Background())
+		defer parent.Stop()
+		parent.SetDecider(func(result *SupervisorActorResult) int {
+			return SUPERVISOR_STOP
+		})
+		child := NewSupervisor(context.Background())
+		parent.SuperviseSupervisor(child)
+		failing := child.SuperviseProps(PropsFromFunc(func(result *ActorResult) *ActorResult {
+			return &ActorResult{Error: errors.New("fatal"), Action: ACTOR_FAIL}
+		}), "failing")
+
+		// Act
+		failing.SendMessage("fail")
+
+		// Assert
+		select {
+		case <-child.stop:
+		case <-time.After(time.Second):
+			t.Fatalf("expected the sub-supervisor to be stopped")
+		}
+		if len(parent.SubSupervisors()) != 0 {
+			t.Errorf("expected the stopped sub-supervisor to be removed")
+		}
+	})
+
+	t.Run("TestEscalationReachesTerminalHandler", func(t *testing.T) {
+		// Arrange
+		terminal := make(chan *SupervisorActorResult, 1)
+		root := NewSupervisor(context.Background())
+		defer root.Stop()
+		root.SetDecider(func(result *SupervisorActorResult) int {
+			return SUPERVISOR_FAIL
+		})
+		root.SetTerminalFailureHandler(func(result *SupervisorActorResult) {
+			terminal <- result
+		})
+		middle := NewSupervisor(context.Background())
+		middle.SetDecider(func(result *SupervisorActorResult) int {
+			return SUPERVISOR_FAIL
+		})
+		root.SuperviseSupervisor(middle)
+		leaf := NewSupervisor(context.Background())
+		middle.SuperviseSupervisor(leaf)
+		failing := leaf.SuperviseProps(PropsFromFunc(func(result *ActorResult) *ActorResult {
+			return &ActorResult{Error: errors.New("fatal"), Action: ACTOR_FAIL}
+		}), "failing")
+
+		// Act
+		failing.SendMessage("fail")
+
+		// Assert
+		select {
+		case result := <-terminal:
+			if result.Result.Error.Error() != "fatal" || result.SupervisorID != middle.GetID() {
+				t.Errorf("expected the leaf failure relayed through the middle layer, got %+v", result)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("expected the failure to reach the terminal handler")
+		}
+	})
+
+	t.Run("TestParentStopStopsSubSupervisor", func(t *testing.T) {
+		// Arrange
+		parent := NewSupervisor(context.Background())
+		child := NewSupervisor(context.Background())
+		parent.SuperviseSupervisor(child)
+
+		// Act
+		parent.Stop()
+
+		// Assert
+		select {
+		case <-child.stop:
+		case <-time.After(time.Second):
+			t.Errorf("expected the sub-supervisor to stop with its parent")
+		}
+	})
+}
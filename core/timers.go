@@ -0,0 +1,57 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// StartTimer sends msg to the actor every interval until CancelTimer is
+// called with the same key. Starting a timer under a key already in use
+// replaces it. Timers are cancelled when the actor stops or restarts. A
+// non-positive interval is logged and the timer is not started.
+func (a *BasicActor) StartTimer(key string, msg interface{}, interval time.Duration) {
+	if interval <= 0 {
+		fmt.Printf("Actor %s ignoring timer %s: interval %s is not positive\n", a.id, key, interval)
+		return
+	}
+	a.startTimer(key, schedule(context.Background(), interval, interval, a, msg))
+}
+
+// StartSingleTimer sends msg to the actor once after delay, unless cancelled
+// with CancelTimer or by the actor stopping or restarting first
+func (a *BasicActor) StartSingleTimer(key string, msg interface{}, delay time.Duration) {
+	a.startTimer(key, scheduleOnce(context.Background(), delay, a, msg))
+}
+
+func (a *BasicActor) startTimer(key string, timer Cancellable) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.timers == nil {
+		a.timers = make(map[string]Cancellable)
+	}
+	if previous, ok := a.timers[key]; ok {
+		previous.Cancel()
+	}
+	a.timers[key] = timer
+}
+
+// CancelTimer stops the timer started under key
+func (a *BasicActor) CancelTimer(key string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if timer, ok := a.timers[key]; ok {
+		timer.Cancel()
+		delete(a.timers, key)
+	}
+}
+
+// cancelTimers stops every timer of the actor
+func (a *BasicActor) cancelTimers() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for key, timer := range a.timers {
+		timer.Cancel()
+		delete(a.timers, key)
+	}
+}
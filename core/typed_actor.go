@@ -0,0 +1,136 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrUnexpectedMessageType is reported when a typed actor receives a message
+// of a type other than the one it was declared with
+var ErrUnexpectedMessageType = errors.New("unexpected message type")
+
+// TypedReceiveFunc handles a message already asserted to the actor's type
+type TypedReceiveFunc[M any] func(msg M, result *ActorResult) *ActorResult
+
+// TypedActor is a BasicActor that only accepts messages of type M. It still
+// implements Actor, so it can be supervised, registered and subscribed to a
+// MessageBroker; untyped messages of the wrong type are rejected by its
+// TypedMailbox and sent to its dead letters.
+type TypedActor[M any] struct {
+	*BasicActor
+	Receive TypedReceiveFunc[M]
+}
+
+// TypedMailbox wraps a mailbox so it only queues messages of type M, failing
+// others with ErrUnexpectedMessageType. System messages skip the mailbox.
+type TypedMailbox[M any] struct {
+	Mailbox
+}
+
+// NewTypedMailbox restricts mailbox to messages of type M
+func NewTypedMailbox[M any](mailbox Mailbox) *TypedMailbox[M] {
+	return &TypedMailbox[M]{Mailbox: mailbox}
+}
+
+func (m *TypedMailbox[M]) Enqueue(msg interface{}) error {
+	if _, ok := unwrapMessage(msg).(M); !ok {
+		return fmt.Errorf("%w: got %T", ErrUnexpectedMessageType, unwrapMessage(msg))
+	}
+	return m.Mailbox.Enqueue(msg)
+}
+
+// Close closes the wrapped mailbox if it can be closed
+func (m *TypedMailbox[M]) Close() {
+	if mailbox, ok := m.Mailbox.(interface{ Close() }); ok {
+		mailbox.Close()
+	}
+}
+
+// NewTypedActor creates a typed actor with the default mailbox size
+func NewTypedActor[M any](name string, receive TypedReceiveFunc[M]) *TypedActor[M] {
+	return NewTypedActorWithMailboxSize(name, 100, receive)
+}
+
+func NewTypedActorWithMailboxSize[M any](name string, size int, receive TypedReceiveFunc[M]) *TypedActor[M] {
+	basic := NewBasicActorWithMailboxSize(name, size)
+	basic.mailbox = NewTypedMailbox[M](basic.mailbox)
+	t := &TypedActor[M]{
+		BasicActor: basic,
+		Receive:    receive,
+	}
+	t.BasicActor.ReceiveFunc = t.receive
+	return t
+}
+
+// typed gives the package access to the TypedActor embedded in user types
+func (t *TypedActor[M]) typed() *TypedActor[M] {
+	return t
+}
+
+// receive asserts the message type again, as messages can still reach the
+// run loop without going through the TypedMailbox, e.g. when unstashed
+func (t *TypedActor[M]) receive(result *ActorResult) *ActorResult {
+	msg, ok := result.Message.(M)
+	if !ok {
+		err := fmt.Errorf("%w: actor %s got %T", ErrUnexpectedMessageType, t.GetName(), result.Message)
+		if result.future != nil {
+			result.future.complete(nil, err)
+		}
+		t.publishDeadLetter(&envelope{message: result.Message, sender: result.Sender}, err)
+		return &ActorResult{}
+	}
+	if t.Receive == nil {
+		return &ActorResult{
+			Error: fmt.Errorf("no receive function defined for actor %s", t.GetID()),
+		}
+	}
+	return t.Receive(msg, result)
+}
+
+// Tell sends a message of the actor's type
+func (t *TypedActor[M]) Tell(msg M) {
+	t.SendMessage(msg)
+}
+
+// Ref returns a typed reference to the actor
+func (t *TypedActor[M]) Ref() ActorRef[M] {
+	return ActorRef[M]{actor: t}
+}
+
+// ActorRef is a handle to an actor that only allows sending messages of type M
+type ActorRef[M any] struct {
+	actor Actor
+}
+
+// NewActorRef wraps an existing actor, e.g. one found in an ActorRegistry.
+// It returns false unless the actor is a TypedActor of M, or embeds one.
+func NewActorRef[M any](actor Actor) (ActorRef[M], bool) {
+	if _, ok := actor.(interface{ typed() *TypedActor[M] }); !ok {
+		return ActorRef[M]{}, false
+	}
+	return ActorRef[M]{actor: actor}, true
+}
+
+func (r ActorRef[M]) Tell(msg M) {
+	r.actor.SendMessage(msg)
+}
+
+func (r ActorRef[M]) Ask(ctx context.Context, msg M) (Future, error) {
+	return Ask(ctx, r.actor, msg)
+}
+
+// Actor returns the untyped actor behind the reference
+func (r ActorRef[M]) Actor() Actor {
+	return r.actor
+}
+
+// GetTypedActor looks up an actor in the registry and returns a typed
+// reference to it, or false if it is missing or not a TypedActor of M
+func GetTypedActor[M any](registry *ActorRegistry, name string) (ActorRef[M], bool) {
+	actor, exists := registry.GetActor(name)
+	if !exists {
+		return ActorRef[M]{}, false
+	}
+	return NewActorRef[M](actor)
+}
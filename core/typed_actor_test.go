@@ -0,0 +1,158 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+type depositMessage struct {
+	Amount int
+}
+
+// Test suite for TypedActor
+func TestTypedActor(t *testing.T) {
+
+	t.Run("TestTypedActorReceivesTypedMessage", func(t *testing.T) {
+		// Arrange
+		received := make(chan int, 1)
+		actor := NewTypedActor("typed-actor", func(msg depositMessage, result *ActorResult) *ActorResult {
+			received <- msg.Amount
+			return &ActorResult{}
+		})
+		actor.Start()
+		defer actor.Stop()
+
+		// Act
+		actor.Tell(depositMessage{Amount: 10})
+
+		// Assert
+		select {
+		case amount := <-received:
+			if amount != 10 {
+				t.Errorf("expected amount 10, got %d", amount)
+			}
+		case <-time.After(time.Second):
+			t.Errorf("expected typed message to be received")
+		}
+	})
+
+	t.Run("TestTypedActorRejectsWrongType", func(t *testing.T) {
+		// Arrange
+		deadLetters, letters := collectDeadLetters(t)
+		actor := NewTypedActor("typed-actor", func(msg depositMessage, result *ActorResult) *ActorResult {
+			result.Reply(msg.Amount)
+			return &ActorResult{}
+		})
+		actor.SetDeadLetters(deadLetters)
+		actor.Start()
+		defer actor.Stop()
+
+		// Act
+		_, err := Ask(context.Background(), actor, "not a deposit")
+		actor.SendMessage(42)
+
+		// Assert
+		if !errors.Is(err, ErrUnexpectedMessageType) {
+			t.Errorf("expected unexpected message type error, got %v", err)
+		}
+		for _, want := range []interface{}{"not a deposit", 42} {
+			letter := expectDeadLetter(t, letters)
+			if letter.Message != want || !errors.Is(letter.Reason, ErrUnexpectedMessageType) {
+				t.Errorf("expected %v as a dead letter, got %v (%v)", want, letter.Message, letter.Reason)
+			}
+		}
+		if actor.MailboxSize() != 0 {
+			t.Errorf("expected the mailbox to reject the messages, got %d queued", actor.MailboxSize())
+		}
+	})
+
+	t.Run("TestTypedActorReceiveRejectsWrongType", func(t *testing.T) {
+		// Arrange
+		deadLetters, letters := collectDeadLetters(t)
+		actor := NewTypedActor("typed-actor", func(msg depositMessage, result *ActorResult) *ActorResult {
+			return &ActorResult{}
+		})
+		actor.SetDeadLetters(deadLetters)
+		sender := NewBasicActor("sender")
+
+		// Act
+		actor.receive(&ActorResult{Message: "not a deposit", Sender: sender})
+
+		// Assert
+		letter := expectDeadLetter(t, letters)
+		if letter.Message != "not a deposit" || letter.Sender != sender || !errors.Is(letter.Reason, ErrUnexpectedMessageType) {
+			t.Errorf("expected the message as a dead letter, got %+v", letter)
+		}
+	})
+
+	t.Run("TestTypedRefFromRegistry", func(t *testing.T) {
+		// Arrange
+		registry := NewActorRegistry()
+		actor := NewTypedActor("account", func(msg depositMessage, result *ActorResult) *ActorResult {
+			result.Reply(msg.Amount * 2)
+			return &ActorResult{}
+		})
+		registry.RegisterActor(actor)
+		actor.Start()
+		defer actor.Stop()
+
+		// Act
+		ref, found := GetTypedActor[depositMessage](registry, "account")
+		if !found {
+			t.Fatalf("expected typed actor to be found")
+		}
+		future, _ := ref.Ask(context.Background(), depositMessage{Amount: 5})
+		value, err := future.Await(context.Background())
+
+		// Assert
+		if err != nil || value != 10 {
+			t.Errorf("expected 10, got %v (%v)", value, err)
+		}
+		if _, found := GetTypedActor[string](registry, "account"); found {
+			t.Errorf("expected a reference of another message type to be refused")
+		}
+	})
+
+	t.Run("TestActorRefRequiresTypedActor", func(t *testing.T) {
+		// Arrange
+		untyped := NewBasicActor("untyped")
+
+		// Act
+		_, ok := NewActorRef[depositMessage](untyped)
+
+		// Assert
+		if ok {
+			t.Errorf("expected a reference to an untyped actor to be refused")
+		}
+	})
+
+	t.Run("TestTypedActorSupervisedAndSubscribed", func(t *testing.T) {
+		// Arrange
+		received := make(chan int, 1)
+		supervisor := NewSupervisor(context.Background())
+		actor := NewTypedActor("typed-subscriber", func(msg depositMessage, result *ActorResult) *ActorResult {
+			received <- msg.Amount
+			return &ActorResult{}
+		})
+		supervisor.SuperviseActor(actor)
+		defer supervisor.Stop()
+
+		broker := NewInMemoryBroker()
+		broker.Subscribe("deposits", actor)
+
+		// Act
+		broker.Publish("deposits", depositMessage{Amount: 7})
+
+		// Assert
+		select {
+		case amount := <-received:
+			if amount != 7 {
+				t.Errorf("expected amount 7, got %d", amount)
+			}
+		case <-time.After(time.Second):
+			t.Errorf("expected published message to be received")
+		}
+	})
+}
@@ -2,7 +2,7 @@ module examples
 
 go 1.22.5
 
-replace github.com/EndlessUpHill/goakka/core v0.0.0 => ../core
+replace github.com/EndlessUpHill/goakka/core => ../core
 
 replace github.com/EndlessUpHill/goakka/redis v0.0.0 => ../redis
 
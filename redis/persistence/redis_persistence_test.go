@@ -0,0 +1,61 @@
+package persistence_test
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/EndlessUpHill/goakka/core/persistence"
+	"github.com/EndlessUpHill/goakka/core/persistence/persistencetest"
+	coreRedis "github.com/EndlessUpHill/goakka/redis"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func newJournal(t *testing.T, server *miniredis.Miniredis) *coreRedis.RedisJournal {
+	journal := coreRedis.NewRedisJournal(server.Addr(), nil)
+	t.Cleanup(func() { journal.Close() })
+	return journal
+}
+
+func newSnapshotStore(t *testing.T, server *miniredis.Miniredis) *coreRedis.RedisSnapshotStore {
+	store := coreRedis.NewRedisSnapshotStore(server.Addr(), nil)
+	t.Cleanup(func() { store.Close() })
+	return store
+}
+
+func TestRedisJournal(t *testing.T) {
+	persistencetest.JournalSuite(t, func(t *testing.T) persistence.Journal {
+		return newJournal(t, miniredis.RunT(t))
+	})
+}
+
+func TestRedisSnapshotStore(t *testing.T) {
+	persistencetest.SnapshotStoreSuite(t, func(t *testing.T) persistence.SnapshotStore {
+		return newSnapshotStore(t, miniredis.RunT(t))
+	})
+}
+
+func TestRedisPersistentActor(t *testing.T) {
+	persistencetest.PersistentActorSuite(t, func(t *testing.T) (persistence.Journal, persistence.SnapshotStore) {
+		server := miniredis.RunT(t)
+		return newJournal(t, server), newSnapshotStore(t, server)
+	})
+}
+
+func TestRedisJournalSecondWriter(t *testing.T) {
+	ctx := context.Background()
+	server := miniredis.RunT(t)
+	journal := newJournal(t, server)
+	other := newJournal(t, server)
+	assert.NoError(t, journal.Write(ctx, persistencetest.Events("account-1", 1, persistencetest.Incremented{By: 10})))
+
+	// A second writer that has not seen event 1 is rejected
+	err := other.Write(ctx, persistencetest.Events("account-1", 1, persistencetest.Incremented{By: 5}))
+	assert.True(t, errors.Is(err, persistence.ErrSequenceConflict))
+
+	replayed := persistencetest.ReplayAll(t, journal, "account-1", 1, math.MaxUint64)
+	assert.Len(t, replayed, 1)
+	assert.Equal(t, persistencetest.Incremented{By: 10}, replayed[0].Payload)
+}
@@ -0,0 +1,216 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/EndlessUpHill/goakka/core/persistence"
+	"github.com/go-redis/redis/v8"
+)
+
+// replayBatchSize is the number of events read per XRANGE call
+const replayBatchSize = 500
+
+// writeEvents appends events to the stream of a persistence ID if the
+// highest sequence number stored is still the expected one. Stream entry IDs
+// are <sequenceNr>-0, so entries are ordered and addressed by sequence number.
+//
+// KEYS[1] stream, KEYS[2] highest sequence number
+// ARGV[1] expected highest sequence number, then sequenceNr, payload,
+// timestamp and tags of every event
+var writeEvents = redis.NewScript(`
+local highest = tonumber(redis.call('GET', KEYS[2]) or '0')
+if highest ~= tonumber(ARGV[1]) then
+	return 0
+end
+for i = 2, #ARGV, 4 do
+	redis.call('XADD', KEYS[1], ARGV[i] .. '-0', 'payload', ARGV[i + 1], 'timestamp', ARGV[i + 2], 'tags', ARGV[i + 3])
+end
+redis.call('SET', KEYS[2], ARGV[#ARGV - 3])
+return 1
+`)
+
+// RedisJournal is a persistence.Journal storing the events of each
+// persistence ID in a Redis stream. Writes are checked against the highest
+// sequence number in a Lua script, so concurrent writers for the same
+// persistence ID cannot interleave events.
+type RedisJournal struct {
+	client     *redis.Client
+	serializer persistence.Serializer
+}
+
+// NewRedisJournal creates a journal connected to redisAddr. A nil serializer
+// defaults to persistence.GobSerializer.
+func NewRedisJournal(redisAddr string, serializer persistence.Serializer) *RedisJournal {
+	fmt.Println("Creating new Redis journal...")
+	if serializer == nil {
+		serializer = persistence.GobSerializer{}
+	}
+	return &RedisJournal{
+		client: redis.NewClient(&redis.Options{
+			Addr: redisAddr,
+		}),
+		serializer: serializer,
+	}
+}
+
+// The persistence ID is a hash tag so a journal's keys share a cluster slot
+func streamKey(persistenceID string) string {
+	return "journal:{" + persistenceID + "}"
+}
+
+func highestKey(persistenceID string) string {
+	return "journal:{" + persistenceID + "}:highest"
+}
+
+func (j *RedisJournal) Write(ctx context.Context, events []persistence.Event) error {
+	if len(events) == 0 {
+		return nil
+	}
+	persistenceID := events[0].PersistenceID
+	first := events[0].SequenceNr
+	if first == 0 {
+		return persistence.ErrSequenceConflict
+	}
+
+	args := []interface{}{first - 1}
+	for i, event := range events {
+		if event.PersistenceID != persistenceID {
+			return errors.New("events of several persistence IDs written at once")
+		}
+		if event.SequenceNr != first+uint64(i) {
+			return persistence.ErrSequenceConflict
+		}
+		payload, err := j.serializer.Marshal(event.Payload)
+		if err != nil {
+			return fmt.Errorf("could not serialize event %d of %s: %w", event.SequenceNr, persistenceID, err)
+		}
+		tags, err := encodeTags(event.Tags)
+		if err != nil {
+			return err
+		}
+		args = append(args, event.SequenceNr, payload, event.Timestamp.UnixNano(), tags)
+	}
+
+	written, err := writeEvents.Run(ctx, j.client, []string{streamKey(persistenceID), highestKey(persistenceID)}, args...).Int()
+	if err != nil {
+		log.Printf("Error writing events of %s: %v", persistenceID, err)
+		return err
+	}
+	if written == 0 {
+		return persistence.ErrSequenceConflict
+	}
+	return nil
+}
+
+func (j *RedisJournal) Replay(ctx context.Context, persistenceID string, fromSequenceNr, toSequenceNr uint64, fn func(persistence.Event) error) error {
+	end := "+"
+	if toSequenceNr < math.MaxUint64 {
+		end = strconv.FormatUint(toSequenceNr, 10) + "-0"
+	}
+	start := strconv.FormatUint(fromSequenceNr, 10) + "-0"
+	for {
+		messages, err := j.client.XRangeN(ctx, streamKey(persistenceID), start, end, replayBatchSize).Result()
+		if err != nil {
+			return err
+		}
+		for _, message := range messages {
+			event, err := j.decode(persistenceID, message)
+			if err != nil {
+				return err
+			}
+			if err := fn(event); err != nil {
+				return err
+			}
+		}
+		if len(messages) < replayBatchSize {
+			return nil
+		}
+		// Exclusive ranges need Redis 6.2, continue after the last entry instead
+		last, _ := sequenceNr(messages[len(messages)-1].ID)
+		start = strconv.FormatUint(last+1, 10) + "-0"
+	}
+}
+
+// decode turns a stream entry back into an event
+func (j *RedisJournal) decode(persistenceID string, message redis.XMessage) (persistence.Event, error) {
+	seq, err := sequenceNr(message.ID)
+	if err != nil {
+		return persistence.Event{}, err
+	}
+	payload, _ := message.Values["payload"].(string)
+	value, err := j.serializer.Unmarshal([]byte(payload))
+	if err != nil {
+		return persistence.Event{}, fmt.Errorf("could not deserialize event %d of %s: %w", seq, persistenceID, err)
+	}
+	timestamp, _ := message.Values["timestamp"].(string)
+	nanos, _ := strconv.ParseInt(timestamp, 10, 64)
+	tags, _ := message.Values["tags"].(string)
+	event := persistence.Event{
+		PersistenceID: persistenceID,
+		SequenceNr:    seq,
+		Payload:       value,
+		Timestamp:     time.Unix(0, nanos),
+	}
+	if tags != "" {
+		if err := json.Unmarshal([]byte(tags), &event.Tags); err != nil {
+			return persistence.Event{}, fmt.Errorf("could not decode the tags of event %d of %s: %w", seq, persistenceID, err)
+		}
+	}
+	return event, nil
+}
+
+// encodeTags stores tags as a JSON array, untagged events as an empty string
+func encodeTags(tags []string) (string, error) {
+	if len(tags) == 0 {
+		return "", nil
+	}
+	encoded, err := json.Marshal(tags)
+	return string(encoded), err
+}
+
+// sequenceNr extracts the sequence number from a stream entry ID
+func sequenceNr(id string) (uint64, error) {
+	seq, _, _ := strings.Cut(id, "-")
+	return strconv.ParseUint(seq, 10, 64)
+}
+
+func (j *RedisJournal) HighestSequenceNr(ctx context.Context, persistenceID string) (uint64, error) {
+	highest, err := j.client.Get(ctx, highestKey(persistenceID)).Uint64()
+	if err == redis.Nil {
+		return 0, nil
+	}
+	return highest, err
+}
+
+func (j *RedisJournal) DeleteTo(ctx context.Context, persistenceID string, toSequenceNr uint64) error {
+	end := "+"
+	if toSequenceNr < math.MaxUint64 {
+		end = strconv.FormatUint(toSequenceNr, 10) + "-0"
+	}
+	for {
+		messages, err := j.client.XRangeN(ctx, streamKey(persistenceID), "-", end, replayBatchSize).Result()
+		if err != nil || len(messages) == 0 {
+			return err
+		}
+		ids := make([]string, len(messages))
+		for i, message := range messages {
+			ids[i] = message.ID
+		}
+		if err := j.client.XDel(ctx, streamKey(persistenceID), ids...).Err(); err != nil {
+			return err
+		}
+	}
+}
+
+// Close closes the connection to Redis
+func (j *RedisJournal) Close() error {
+	return j.client.Close()
+}
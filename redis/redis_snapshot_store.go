@@ -0,0 +1,109 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/EndlessUpHill/goakka/core/persistence"
+	"github.com/go-redis/redis/v8"
+)
+
+// RedisSnapshotStore is a persistence.SnapshotStore keeping each snapshot in
+// a Redis hash, indexed by sequence number in a sorted set per persistence ID
+type RedisSnapshotStore struct {
+	client     *redis.Client
+	serializer persistence.Serializer
+}
+
+// NewRedisSnapshotStore creates a snapshot store connected to redisAddr. A
+// nil serializer defaults to persistence.GobSerializer.
+func NewRedisSnapshotStore(redisAddr string, serializer persistence.Serializer) *RedisSnapshotStore {
+	fmt.Println("Creating new Redis snapshot store...")
+	if serializer == nil {
+		serializer = persistence.GobSerializer{}
+	}
+	return &RedisSnapshotStore{
+		client: redis.NewClient(&redis.Options{
+			Addr: redisAddr,
+		}),
+		serializer: serializer,
+	}
+}
+
+func snapshotIndexKey(persistenceID string) string {
+	return "snapshots:{" + persistenceID + "}"
+}
+
+func snapshotKey(persistenceID string, sequenceNr uint64) string {
+	return "snapshots:{" + persistenceID + "}:" + strconv.FormatUint(sequenceNr, 10)
+}
+
+func (s *RedisSnapshotStore) Save(ctx context.Context, snapshot persistence.Snapshot) error {
+	state, err := s.serializer.Marshal(snapshot.State)
+	if err != nil {
+		return fmt.Errorf("could not serialize snapshot %d of %s: %w", snapshot.SequenceNr, snapshot.PersistenceID, err)
+	}
+	_, err = s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HSet(ctx, snapshotKey(snapshot.PersistenceID, snapshot.SequenceNr),
+			"state", state,
+			"timestamp", snapshot.Timestamp.UnixNano(),
+		)
+		pipe.ZAdd(ctx, snapshotIndexKey(snapshot.PersistenceID), &redis.Z{
+			Score:  float64(snapshot.SequenceNr),
+			Member: snapshot.SequenceNr,
+		})
+		return nil
+	})
+	return err
+}
+
+func (s *RedisSnapshotStore) Load(ctx context.Context, persistenceID string) (persistence.Snapshot, bool, error) {
+	latest, err := s.client.ZRevRange(ctx, snapshotIndexKey(persistenceID), 0, 0).Result()
+	if err != nil || len(latest) == 0 {
+		return persistence.Snapshot{}, false, err
+	}
+	sequenceNr, err := strconv.ParseUint(latest[0], 10, 64)
+	if err != nil {
+		return persistence.Snapshot{}, false, err
+	}
+
+	fields, err := s.client.HGetAll(ctx, snapshotKey(persistenceID, sequenceNr)).Result()
+	if err != nil {
+		return persistence.Snapshot{}, false, err
+	}
+	state, err := s.serializer.Unmarshal([]byte(fields["state"]))
+	if err != nil {
+		return persistence.Snapshot{}, false, fmt.Errorf("could not deserialize snapshot %d of %s: %w", sequenceNr, persistenceID, err)
+	}
+	nanos, _ := strconv.ParseInt(fields["timestamp"], 10, 64)
+	return persistence.Snapshot{
+		PersistenceID: persistenceID,
+		SequenceNr:    sequenceNr,
+		State:         state,
+		Timestamp:     time.Unix(0, nanos),
+	}, true, nil
+}
+
+func (s *RedisSnapshotStore) DeleteTo(ctx context.Context, persistenceID string, toSequenceNr uint64) error {
+	max := strconv.FormatUint(toSequenceNr, 10)
+	deleted, err := s.client.ZRangeByScore(ctx, snapshotIndexKey(persistenceID), &redis.ZRangeBy{Min: "-inf", Max: max}).Result()
+	if err != nil || len(deleted) == 0 {
+		return err
+	}
+	_, err = s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		for _, member := range deleted {
+			sequenceNr, _ := strconv.ParseUint(member, 10, 64)
+			pipe.Del(ctx, snapshotKey(persistenceID, sequenceNr))
+		}
+		pipe.ZRemRangeByScore(ctx, snapshotIndexKey(persistenceID), "-inf", max)
+		return nil
+	})
+	return err
+}
+
+// Close closes the connection to Redis
+func (s *RedisSnapshotStore) Close() error {
+	return s.client.Close()
+}
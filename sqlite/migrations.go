@@ -0,0 +1,96 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+
+	_ "modernc.org/sqlite"
+)
+
+// migrations bring the schema from one version to the next, migrations[i]
+// creating version i+1. The version is kept in PRAGMA user_version. Never
+// change a migration once released, append a new one instead.
+var migrations = []string{
+	// 1: journal, events keep a global ordering so tag queries can resume
+	// from an offset
+	`CREATE TABLE journal (
+		ordering       INTEGER PRIMARY KEY AUTOINCREMENT,
+		persistence_id TEXT    NOT NULL,
+		sequence_nr    INTEGER NOT NULL,
+		payload        BLOB,
+		timestamp      INTEGER NOT NULL,
+		deleted        INTEGER NOT NULL DEFAULT 0,
+		UNIQUE (persistence_id, sequence_nr)
+	);
+	CREATE TABLE event_tags (
+		ordering INTEGER NOT NULL REFERENCES journal (ordering) ON DELETE CASCADE,
+		tag      TEXT    NOT NULL,
+		PRIMARY KEY (tag, ordering)
+	);
+	CREATE INDEX event_tags_ordering ON event_tags (ordering);`,
+
+	// 2: snapshots
+	`CREATE TABLE snapshots (
+		persistence_id TEXT    NOT NULL,
+		sequence_nr    INTEGER NOT NULL,
+		state          BLOB    NOT NULL,
+		timestamp      INTEGER NOT NULL,
+		PRIMARY KEY (persistence_id, sequence_nr)
+	);`,
+}
+
+// open opens the database at path, creating it if missing, and migrates its
+// schema. WAL lets replays and tag queries run while events are written,
+// transactions take the write lock immediately so concurrent writers wait
+// on busy_timeout instead of failing.
+func open(path string) (*sql.DB, error) {
+	dsn := "file:" + path +
+		"?_pragma=journal_mode(WAL)" +
+		"&_pragma=synchronous(FULL)" +
+		"&_pragma=busy_timeout(5000)" +
+		"&_pragma=foreign_keys(1)" +
+		"&_txlock=immediate"
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, err
+	}
+	if err := migrate(context.Background(), db); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+// migrate applies the migrations the database has not seen yet in a single
+// transaction, so a failed migration leaves the schema untouched
+func migrate(ctx context.Context, db *sql.DB) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var version int
+	if err := tx.QueryRowContext(ctx, "PRAGMA user_version").Scan(&version); err != nil {
+		return err
+	}
+	if version > len(migrations) {
+		return fmt.Errorf("database schema version %d is newer than the supported version %d", version, len(migrations))
+	}
+	if version == len(migrations) {
+		return nil
+	}
+	for i := version; i < len(migrations); i++ {
+		log.Printf("Migrating SQLite schema to version %d", i+1)
+		if _, err := tx.ExecContext(ctx, migrations[i]); err != nil {
+			return fmt.Errorf("could not migrate schema to version %d: %w", i+1, err)
+		}
+	}
+	// PRAGMA does not take parameters
+	if _, err := tx.ExecContext(ctx, fmt.Sprintf("PRAGMA user_version = %d", len(migrations))); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,126 @@
+package persistence_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"math"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/EndlessUpHill/goakka/core/persistence"
+	"github.com/EndlessUpHill/goakka/core/persistence/persistencetest"
+	coreSQLite "github.com/EndlessUpHill/goakka/sqlite"
+	"github.com/stretchr/testify/assert"
+	_ "modernc.org/sqlite"
+)
+
+func newJournal(t *testing.T, path string) *coreSQLite.SQLiteJournal {
+	journal, err := coreSQLite.NewSQLiteJournal(path, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	t.Cleanup(func() { journal.Close() })
+	return journal
+}
+
+func newSnapshotStore(t *testing.T, path string) *coreSQLite.SQLiteSnapshotStore {
+	store, err := coreSQLite.NewSQLiteSnapshotStore(path, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+	return store
+}
+
+func dbPath(t *testing.T) string {
+	return filepath.Join(t.TempDir(), "goakka.db")
+}
+
+func TestSQLiteJournal(t *testing.T) {
+	persistencetest.JournalSuite(t, func(t *testing.T) persistence.Journal {
+		return newJournal(t, dbPath(t))
+	})
+}
+
+func TestSQLiteSnapshotStore(t *testing.T) {
+	persistencetest.SnapshotStoreSuite(t, func(t *testing.T) persistence.SnapshotStore {
+		return newSnapshotStore(t, dbPath(t))
+	})
+}
+
+func TestSQLitePersistentActor(t *testing.T) {
+	persistencetest.PersistentActorSuite(t, func(t *testing.T) (persistence.Journal, persistence.SnapshotStore) {
+		// The journal and snapshot store share the database file
+		path := dbPath(t)
+		return newJournal(t, path), newSnapshotStore(t, path)
+	})
+}
+
+func TestSQLiteJournalReopen(t *testing.T) {
+	ctx := context.Background()
+	path := dbPath(t)
+	first := newJournal(t, path)
+	assert.NoError(t, first.Write(ctx, persistencetest.Events("account-1", 1, persistencetest.Incremented{By: 10}, persistencetest.Incremented{By: 20})))
+	assert.NoError(t, first.Close())
+
+	// Reopening does not migrate again nor lose events
+	second := newJournal(t, path)
+	highest, err := second.HighestSequenceNr(ctx, "account-1")
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(2), highest)
+	assert.Len(t, persistencetest.ReplayAll(t, second, "account-1", 1, math.MaxUint64), 2)
+
+	db, err := sql.Open("sqlite", path)
+	assert.NoError(t, err)
+	defer db.Close()
+	var version int
+	assert.NoError(t, db.QueryRow("PRAGMA user_version").Scan(&version))
+	assert.Equal(t, 2, version)
+}
+
+func TestSQLiteJournalSecondWriter(t *testing.T) {
+	ctx := context.Background()
+	path := dbPath(t)
+	journal := newJournal(t, path)
+	other := newJournal(t, path)
+	assert.NoError(t, journal.Write(ctx, persistencetest.Events("account-1", 1, persistencetest.Incremented{By: 10})))
+
+	// A second writer that has not seen event 1 is rejected
+	err := other.Write(ctx, persistencetest.Events("account-1", 1, persistencetest.Incremented{By: 5}))
+	assert.True(t, errors.Is(err, persistence.ErrSequenceConflict))
+
+	replayed := persistencetest.ReplayAll(t, journal, "account-1", 1, math.MaxUint64)
+	assert.Len(t, replayed, 1)
+	assert.Equal(t, persistencetest.Incremented{By: 10}, replayed[0].Payload)
+}
+
+func TestSQLiteJournalConcurrentWrites(t *testing.T) {
+	ctx := context.Background()
+	journal := newJournal(t, dbPath(t))
+	var wg sync.WaitGroup
+	errs := make(chan error, 40)
+	for _, id := range []string{"account-1", "account-2", "account-3", "account-4"} {
+		wg.Add(1)
+		go func(id string) {
+			defer wg.Done()
+			for seq := uint64(1); seq <= 10; seq++ {
+				errs <- journal.Write(ctx, persistencetest.Events(id, seq, persistencetest.Incremented{By: int(seq)}))
+			}
+		}(id)
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		assert.NoError(t, err)
+	}
+	for _, id := range []string{"account-1", "account-2", "account-3", "account-4"} {
+		replayed := persistencetest.ReplayAll(t, journal, id, 1, math.MaxUint64)
+		assert.Len(t, replayed, 10)
+		for i, event := range replayed {
+			assert.Equal(t, uint64(i+1), event.SequenceNr)
+		}
+	}
+}
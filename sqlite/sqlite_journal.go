@@ -0,0 +1,372 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/EndlessUpHill/goakka/core/persistence"
+)
+
+const (
+	// replayBatchSize is the number of events read per query, so no read
+	// transaction stays open while the callbacks run
+	replayBatchSize = 500
+	// maxWriteBatch is the number of queued writes committed together
+	maxWriteBatch = 64
+)
+
+// ErrClosed is returned by writes to a closed journal
+var ErrClosed = errors.New("sqlite journal closed")
+
+// SQLiteJournal is a persistence.Journal storing events in a SQLite database,
+// for single node deployments without Redis or NATS. Writes are queued to a
+// single goroutine committing whatever is waiting in one transaction, so
+// concurrent persistent actors share the cost of syncing to disk. It also
+// implements persistence.EventsByTagQuery.
+type SQLiteJournal struct {
+	db         *sql.DB
+	serializer persistence.Serializer
+	writes     chan *writeRequest
+	done       chan struct{}
+	closeOnce  sync.Once
+	wg         sync.WaitGroup
+}
+
+// writeRequest is a Write waiting for the writer goroutine
+type writeRequest struct {
+	persistenceID string
+	events        []persistence.Event
+	payloads      [][]byte
+	result        chan error
+}
+
+// NewSQLiteJournal opens or creates the database at path and migrates its
+// schema. A nil serializer defaults to persistence.GobSerializer.
+func NewSQLiteJournal(path string, serializer persistence.Serializer) (*SQLiteJournal, error) {
+	fmt.Println("Creating new SQLite journal...")
+	if serializer == nil {
+		serializer = persistence.GobSerializer{}
+	}
+	db, err := open(path)
+	if err != nil {
+		return nil, err
+	}
+	j := &SQLiteJournal{
+		db:         db,
+		serializer: serializer,
+		writes:     make(chan *writeRequest),
+		done:       make(chan struct{}),
+	}
+	j.wg.Add(1)
+	go j.writeLoop()
+	return j, nil
+}
+
+// toInt64 clamps sequence numbers and offsets to what SQLite integers hold
+func toInt64(n uint64) int64 {
+	if n > math.MaxInt64 {
+		return math.MaxInt64
+	}
+	return int64(n)
+}
+
+// Write queues the events for the writer goroutine and waits for the commit.
+// Once queued, a write may still be committed after ctx is done.
+func (j *SQLiteJournal) Write(ctx context.Context, events []persistence.Event) error {
+	if len(events) == 0 {
+		return nil
+	}
+	persistenceID := events[0].PersistenceID
+	first := events[0].SequenceNr
+	if first == 0 {
+		return persistence.ErrSequenceConflict
+	}
+
+	request := &writeRequest{
+		persistenceID: persistenceID,
+		events:        events,
+		payloads:      make([][]byte, len(events)),
+		result:        make(chan error, 1),
+	}
+	for i, event := range events {
+		if event.PersistenceID != persistenceID {
+			return errors.New("events of several persistence IDs written at once")
+		}
+		if event.SequenceNr != first+uint64(i) {
+			return persistence.ErrSequenceConflict
+		}
+		payload, err := j.serializer.Marshal(event.Payload)
+		if err != nil {
+			return fmt.Errorf("could not serialize event %d of %s: %w", event.SequenceNr, persistenceID, err)
+		}
+		request.payloads[i] = payload
+	}
+
+	select {
+	case j.writes <- request:
+	case <-j.done:
+		return ErrClosed
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	select {
+	case err := <-request.result:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// writeLoop commits the queued writes until the journal is closed
+func (j *SQLiteJournal) writeLoop() {
+	defer j.wg.Done()
+	for {
+		select {
+		case request := <-j.writes:
+			batch := []*writeRequest{request}
+		drain:
+			for len(batch) < maxWriteBatch {
+				select {
+				case request := <-j.writes:
+					batch = append(batch, request)
+				default:
+					break drain
+				}
+			}
+			j.commit(batch)
+		case <-j.done:
+			return
+		}
+	}
+}
+
+// commit writes a batch in one transaction. Every request gets a savepoint,
+// so a conflicting request is rolled back without failing the others.
+func (j *SQLiteJournal) commit(batch []*writeRequest) {
+	ctx := context.Background()
+	results := make([]error, len(batch))
+	err := func() error {
+		tx, err := j.db.BeginTx(ctx, nil)
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback()
+		for i, request := range batch {
+			if _, err := tx.ExecContext(ctx, "SAVEPOINT write_request"); err != nil {
+				return err
+			}
+			results[i] = j.insert(ctx, tx, request)
+			if results[i] != nil {
+				if _, err := tx.ExecContext(ctx, "ROLLBACK TO write_request"); err != nil {
+					return err
+				}
+			}
+			if _, err := tx.ExecContext(ctx, "RELEASE write_request"); err != nil {
+				return err
+			}
+		}
+		return tx.Commit()
+	}()
+	if err != nil {
+		log.Printf("Error committing %d writes: %v", len(batch), err)
+	}
+	for i, request := range batch {
+		if err != nil && results[i] == nil {
+			results[i] = err
+		}
+		request.result <- results[i]
+	}
+}
+
+// insert writes the events and tags of a request, after checking they
+// follow the highest sequence number stored
+func (j *SQLiteJournal) insert(ctx context.Context, tx *sql.Tx, request *writeRequest) error {
+	highest, err := highestSequenceNr(ctx, tx, request.persistenceID)
+	if err != nil {
+		return err
+	}
+	if request.events[0].SequenceNr != highest+1 {
+		return persistence.ErrSequenceConflict
+	}
+	for i, event := range request.events {
+		result, err := tx.ExecContext(ctx,
+			"INSERT INTO journal (persistence_id, sequence_nr, payload, timestamp) VALUES (?, ?, ?, ?)",
+			event.PersistenceID, toInt64(event.SequenceNr), request.payloads[i], event.Timestamp.UnixNano())
+		if err != nil {
+			return err
+		}
+		if len(event.Tags) == 0 {
+			continue
+		}
+		ordering, err := result.LastInsertId()
+		if err != nil {
+			return err
+		}
+		for _, tag := range event.Tags {
+			if _, err := tx.ExecContext(ctx, "INSERT OR IGNORE INTO event_tags (ordering, tag) VALUES (?, ?)", ordering, tag); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// querier is implemented by *sql.DB and *sql.Tx
+type querier interface {
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+func highestSequenceNr(ctx context.Context, q querier, persistenceID string) (uint64, error) {
+	var highest int64
+	err := q.QueryRowContext(ctx,
+		"SELECT COALESCE(MAX(sequence_nr), 0) FROM journal WHERE persistence_id = ?", persistenceID).Scan(&highest)
+	return uint64(highest), err
+}
+
+// eventColumns selects the columns read by scanEvents, tags as a JSON array
+const eventColumns = `j.ordering, j.persistence_id, j.sequence_nr, j.payload, j.timestamp,
+	(SELECT json_group_array(tag) FROM event_tags t WHERE t.ordering = j.ordering)`
+
+// storedEvent is an event with its position in the journal
+type storedEvent struct {
+	ordering uint64
+	event    persistence.Event
+}
+
+// readEvents runs a query selecting eventColumns and decodes the events
+func (j *SQLiteJournal) readEvents(ctx context.Context, query string, args ...interface{}) ([]storedEvent, error) {
+	rows, err := j.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var stored []storedEvent
+	for rows.Next() {
+		var (
+			ordering, sequenceNr, nanos int64
+			persistenceID, tags         string
+			payload                     []byte
+		)
+		if err := rows.Scan(&ordering, &persistenceID, &sequenceNr, &payload, &nanos, &tags); err != nil {
+			return nil, err
+		}
+		value, err := j.serializer.Unmarshal(payload)
+		if err != nil {
+			return nil, fmt.Errorf("could not deserialize event %d of %s: %w", sequenceNr, persistenceID, err)
+		}
+		event := persistence.Event{
+			PersistenceID: persistenceID,
+			SequenceNr:    uint64(sequenceNr),
+			Payload:       value,
+			Timestamp:     time.Unix(0, nanos),
+		}
+		if err := json.Unmarshal([]byte(tags), &event.Tags); err != nil {
+			return nil, err
+		}
+		if len(event.Tags) == 0 {
+			event.Tags = nil
+		}
+		stored = append(stored, storedEvent{ordering: uint64(ordering), event: event})
+	}
+	return stored, rows.Err()
+}
+
+func (j *SQLiteJournal) Replay(ctx context.Context, persistenceID string, fromSequenceNr, toSequenceNr uint64, fn func(persistence.Event) error) error {
+	for from := fromSequenceNr; ; {
+		batch, err := j.readEvents(ctx,
+			"SELECT "+eventColumns+` FROM journal j
+			WHERE j.persistence_id = ? AND j.sequence_nr BETWEEN ? AND ? AND j.deleted = 0
+			ORDER BY j.sequence_nr LIMIT ?`,
+			persistenceID, toInt64(from), toInt64(toSequenceNr), replayBatchSize)
+		if err != nil {
+			return err
+		}
+		for _, stored := range batch {
+			if err := fn(stored.event); err != nil {
+				return err
+			}
+		}
+		if len(batch) < replayBatchSize {
+			return nil
+		}
+		from = batch[len(batch)-1].event.SequenceNr + 1
+	}
+}
+
+// EventsByTag calls fn with the events tagged with tag from fromOffset on.
+// The offset of an event is its position in the journal, so a projection
+// resumes by passing the last offset it handled plus one.
+func (j *SQLiteJournal) EventsByTag(ctx context.Context, tag string, fromOffset uint64, fn func(offset uint64, event persistence.Event) error) error {
+	for from := fromOffset; ; {
+		batch, err := j.readEvents(ctx,
+			"SELECT "+eventColumns+` FROM event_tags tagged JOIN journal j ON j.ordering = tagged.ordering
+			WHERE tagged.tag = ? AND tagged.ordering >= ? AND j.deleted = 0
+			ORDER BY tagged.ordering LIMIT ?`,
+			tag, toInt64(from), replayBatchSize)
+		if err != nil {
+			return err
+		}
+		for _, stored := range batch {
+			if err := fn(stored.ordering, stored.event); err != nil {
+				return err
+			}
+		}
+		if len(batch) < replayBatchSize {
+			return nil
+		}
+		from = batch[len(batch)-1].ordering + 1
+	}
+}
+
+func (j *SQLiteJournal) HighestSequenceNr(ctx context.Context, persistenceID string) (uint64, error) {
+	return highestSequenceNr(ctx, j.db, persistenceID)
+}
+
+// DeleteTo removes the events up to toSequenceNr. The highest event is only
+// marked deleted, so sequence numbers keep counting after it.
+func (j *SQLiteJournal) DeleteTo(ctx context.Context, persistenceID string, toSequenceNr uint64) error {
+	tx, err := j.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	highest, err := highestSequenceNr(ctx, tx, persistenceID)
+	if err != nil {
+		return err
+	}
+	to := toInt64(toSequenceNr)
+	if _, err := tx.ExecContext(ctx,
+		"DELETE FROM journal WHERE persistence_id = ? AND sequence_nr <= ? AND sequence_nr < ?",
+		persistenceID, to, toInt64(highest)); err != nil {
+		return err
+	}
+	if toSequenceNr >= highest {
+		if _, err := tx.ExecContext(ctx,
+			"UPDATE journal SET deleted = 1, payload = NULL WHERE persistence_id = ? AND sequence_nr = ?",
+			persistenceID, toInt64(highest)); err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx,
+			"DELETE FROM event_tags WHERE ordering IN (SELECT ordering FROM journal WHERE persistence_id = ? AND deleted = 1)",
+			persistenceID); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// Close stops the writer goroutine and closes the database. Writes queued
+// before Close are committed.
+func (j *SQLiteJournal) Close() error {
+	j.closeOnce.Do(func() { close(j.done) })
+	j.wg.Wait()
+	return j.db.Close()
+}
@@ -0,0 +1,84 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/EndlessUpHill/goakka/core/persistence"
+)
+
+// SQLiteSnapshotStore is a persistence.SnapshotStore keeping snapshots in a
+// SQLite database. It can share the database file of a SQLiteJournal.
+type SQLiteSnapshotStore struct {
+	db         *sql.DB
+	serializer persistence.Serializer
+}
+
+// NewSQLiteSnapshotStore opens or creates the database at path and migrates
+// its schema. A nil serializer defaults to persistence.GobSerializer.
+func NewSQLiteSnapshotStore(path string, serializer persistence.Serializer) (*SQLiteSnapshotStore, error) {
+	fmt.Println("Creating new SQLite snapshot store...")
+	if serializer == nil {
+		serializer = persistence.GobSerializer{}
+	}
+	db, err := open(path)
+	if err != nil {
+		return nil, err
+	}
+	return &SQLiteSnapshotStore{
+		db:         db,
+		serializer: serializer,
+	}, nil
+}
+
+func (s *SQLiteSnapshotStore) Save(ctx context.Context, snapshot persistence.Snapshot) error {
+	state, err := s.serializer.Marshal(snapshot.State)
+	if err != nil {
+		return fmt.Errorf("could not serialize snapshot %d of %s: %w", snapshot.SequenceNr, snapshot.PersistenceID, err)
+	}
+	_, err = s.db.ExecContext(ctx,
+		"INSERT OR REPLACE INTO snapshots (persistence_id, sequence_nr, state, timestamp) VALUES (?, ?, ?, ?)",
+		snapshot.PersistenceID, toInt64(snapshot.SequenceNr), state, snapshot.Timestamp.UnixNano())
+	return err
+}
+
+func (s *SQLiteSnapshotStore) Load(ctx context.Context, persistenceID string) (persistence.Snapshot, bool, error) {
+	var (
+		sequenceNr, nanos int64
+		state             []byte
+	)
+	err := s.db.QueryRowContext(ctx,
+		"SELECT sequence_nr, state, timestamp FROM snapshots WHERE persistence_id = ? ORDER BY sequence_nr DESC LIMIT 1",
+		persistenceID).Scan(&sequenceNr, &state, &nanos)
+	if errors.Is(err, sql.ErrNoRows) {
+		return persistence.Snapshot{}, false, nil
+	}
+	if err != nil {
+		return persistence.Snapshot{}, false, err
+	}
+	value, err := s.serializer.Unmarshal(state)
+	if err != nil {
+		return persistence.Snapshot{}, false, fmt.Errorf("could not deserialize snapshot %d of %s: %w", sequenceNr, persistenceID, err)
+	}
+	return persistence.Snapshot{
+		PersistenceID: persistenceID,
+		SequenceNr:    uint64(sequenceNr),
+		State:         value,
+		Timestamp:     time.Unix(0, nanos),
+	}, true, nil
+}
+
+func (s *SQLiteSnapshotStore) DeleteTo(ctx context.Context, persistenceID string, toSequenceNr uint64) error {
+	_, err := s.db.ExecContext(ctx,
+		"DELETE FROM snapshots WHERE persistence_id = ? AND sequence_nr <= ?",
+		persistenceID, toInt64(toSequenceNr))
+	return err
+}
+
+// Close closes the database
+func (s *SQLiteSnapshotStore) Close() error {
+	return s.db.Close()
+}